@@ -0,0 +1,80 @@
+package extension
+
+import (
+	"fmt"
+	"math"
+)
+
+// intArg reads an integer argument. JSON numbers arrive as float64, so both
+// float64 values without a fractional part and native Go integers are accepted.
+// The boolean result reports whether the key was present.
+func intArg(args map[string]any, key string) (int, bool, error) {
+	v, ok := args[key]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+
+	switch n := v.(type) {
+	case int:
+		return n, true, nil
+	case int64:
+		return int(n), true, nil
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, true, fmt.Errorf("%s must be an integer", key)
+		}
+		return int(n), true, nil
+	default:
+		return 0, true, fmt.Errorf("%s must be an integer", key)
+	}
+}
+
+// stringMapArg reads an object argument whose values must all be strings,
+// such as labels or annotations. A missing key yields a nil map.
+func stringMapArg(args map[string]any, key string) (map[string]string, error) {
+	v, ok := args[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object", key)
+	}
+
+	result := make(map[string]string, len(m))
+	for k, val := range m {
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s must be a string", key, k)
+		}
+		result[k] = s
+	}
+	return result, nil
+}
+
+// stringListArg reads a list argument whose items must all be strings, such
+// as groups. A missing key yields a nil slice.
+func stringListArg(args map[string]any, key string) ([]string, error) {
+	v, ok := args[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+
+	switch list := v.(type) {
+	case []string:
+		return list, nil
+	case []any:
+		result := make([]string, len(list))
+		for i, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s[%d] must be a string", key, i)
+			}
+			result[i] = s
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list of strings", key)
+	}
+}
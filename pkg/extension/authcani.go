@@ -3,10 +3,242 @@ package extension
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// AccessRequest describes a SubjectAccessReview: the subject and either the
+// resource attributes or the non-resource URL to check.
+type AccessRequest struct {
+	User   string
+	Groups []string
+	UID    string
+	Extra  map[string][]string
+
+	Verb           string
+	Resource       string
+	Subresource    string
+	APIGroup       string
+	Namespace      string
+	ResourceName   string
+	NonResourceURL string
+}
+
+// self reports whether the request checks the client's own identity.
+func (r AccessRequest) self() bool {
+	return r.User == "" && len(r.Groups) == 0
+}
+
+// subject describes who the request is checked for.
+func (r AccessRequest) subject() string {
+	switch {
+	case r.User != "":
+		return r.User
+	case len(r.Groups) > 0:
+		return "groups " + strings.Join(r.Groups, ",")
+	}
+	return "current identity"
+}
+
+// target describes the checked resource, e.g. "deployments.apps/web in namespace team-a".
+func (r AccessRequest) target() string {
+	if r.NonResourceURL != "" {
+		return r.NonResourceURL
+	}
+	s := r.Resource
+	if r.APIGroup != "" {
+		s += "." + r.APIGroup
+	}
+	if r.ResourceName != "" {
+		s += "/" + r.ResourceName
+	}
+	if r.Subresource != "" {
+		s += "/" + r.Subresource
+	}
+	if r.Namespace != "" {
+		return s + " in namespace " + r.Namespace
+	}
+	return s + " cluster-wide"
+}
+
+// validate checks that the request names a verb and either a resource or a
+// non-resource URL.
+func (r AccessRequest) validate() error {
+	if r.self() && (r.UID != "" || len(r.Extra) > 0) {
+		return fmt.Errorf("uid and extra require as or groups")
+	}
+	if r.Verb == "" {
+		return fmt.Errorf("verb is required")
+	}
+	if r.NonResourceURL != "" {
+		if !strings.HasPrefix(r.NonResourceURL, "/") {
+			return fmt.Errorf("nonResourceURL must start with /")
+		}
+		if r.Resource != "" || r.Subresource != "" || r.APIGroup != "" || r.Namespace != "" || r.ResourceName != "" {
+			return fmt.Errorf("nonResourceURL cannot be combined with resource, subresource, apiGroup, namespace or resourceName")
+		}
+		return nil
+	}
+	if r.Resource == "" {
+		return fmt.Errorf("resource is required")
+	}
+	return nil
+}
+
+// parseAccessSubject reads the subject arguments: as, groups, uid and extra.
+func parseAccessSubject(args map[string]any) (AccessRequest, error) {
+	var req AccessRequest
+	req.User, _ = args["as"].(string)
+	req.UID, _ = args["uid"].(string)
+
+	groups, err := stringListArg(args, "groups")
+	if err != nil {
+		return req, err
+	}
+	req.Groups = groups
+
+	if raw, ok := args["extra"]; ok && raw != nil {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return req, fmt.Errorf("extra must be an object")
+		}
+		req.Extra = make(map[string][]string, len(m))
+		for k, v := range m {
+			switch v := v.(type) {
+			case string:
+				req.Extra[k] = []string{v}
+			case []any:
+				values, err := stringListArg(m, k)
+				if err != nil {
+					return req, fmt.Errorf("extra.%s must be a string or a list of strings", k)
+				}
+				req.Extra[k] = values
+			default:
+				return req, fmt.Errorf("extra.%s must be a string or a list of strings", k)
+			}
+		}
+	}
+
+	return req, nil
+}
+
+// parseAccessAttributes reads the resource or non-resource attributes of a check.
+func parseAccessAttributes(req *AccessRequest, args map[string]any) {
+	req.Verb, _ = args["verb"].(string)
+	req.Resource, _ = args["resource"].(string)
+	req.Subresource, _ = args["subresource"].(string)
+	req.APIGroup, _ = args["apiGroup"].(string)
+	req.ResourceName, _ = args["resourceName"].(string)
+	req.NonResourceURL, _ = args["nonResourceURL"].(string)
+	if ns, ok := args["namespace"].(string); ok {
+		req.Namespace = ns
+	}
+}
+
+// resolveResource resolves the resource of an access request against the
+// preferred resources in lists. Plural, singular, kind and short names are
+// accepted, as are "resource.group" and "resource/subresource". The apiGroup is
+// inferred when only one group serves the resource; the core group is
+// preferred when no apiGroup is given, as in kubectl. Preferred resources do
+// not include subresources, so those are looked up in the resource's group
+// version through client. Wildcards and non-resource URLs are left unchanged.
+func (r *AccessRequest) resolveResource(ctx context.Context, client ResourceClient, lists []*metav1.APIResourceList) error {
+	if r.NonResourceURL != "" || r.Resource == "*" {
+		return nil
+	}
+
+	name := r.Resource
+	if resource, subresource, ok := strings.Cut(name, "/"); ok {
+		if r.Subresource != "" {
+			return fmt.Errorf("resource %q already names a subresource, cannot combine it with subresource %q", name, r.Subresource)
+		}
+		name, r.Subresource = resource, subresource
+	}
+
+	group := r.APIGroup
+	if resource, g, ok := strings.Cut(name, "."); ok && group == "" {
+		name, group = resource, g
+	}
+
+	type match struct {
+		group    string
+		resource string
+		// groupVersion is the preferred version the resource was found in.
+		groupVersion string
+	}
+	var matches []match
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil || (group != "" && gv.Group != group) {
+			continue
+		}
+		for _, res := range list.APIResources {
+			if strings.Contains(res.Name, "/") || !resourceNameMatches(res, name) {
+				continue
+			}
+			if !slices.ContainsFunc(matches, func(m match) bool { return m.group == gv.Group && m.resource == res.Name }) {
+				matches = append(matches, match{group: gv.Group, resource: res.Name, groupVersion: list.GroupVersion})
+			}
+		}
+	}
+
+	if len(matches) > 1 && group == "" {
+		if i := slices.IndexFunc(matches, func(m match) bool { return m.group == "" }); i >= 0 {
+			matches = matches[i : i+1]
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if group != "" {
+			return fmt.Errorf("unknown resource %q in API group %q", name, group)
+		}
+		return fmt.Errorf("unknown resource %q", name)
+	case 1:
+	default:
+		groups := make([]string, len(matches))
+		for i, m := range matches {
+			groups[i] = m.group
+		}
+		return fmt.Errorf("resource %q is ambiguous, set apiGroup to one of: %s", name, strings.Join(groups, ", "))
+	}
+
+	r.Resource, r.APIGroup = matches[0].resource, matches[0].group
+
+	if r.Subresource == "" {
+		return nil
+	}
+	list, err := client.DiscoverGroupVersion(ctx, matches[0].groupVersion)
+	if err != nil {
+		return fmt.Errorf("failed to discover subresources of %s: %w", r.Resource, err)
+	}
+	if !hasSubresource(list, r.Resource+"/"+r.Subresource) {
+		return fmt.Errorf("unknown subresource %q of %s", r.Subresource, r.Resource)
+	}
+	return nil
+}
+
+// resourceNameMatches reports whether name refers to a discovered resource by
+// its plural, singular, kind or short name.
+func resourceNameMatches(res metav1.APIResource, name string) bool {
+	lower := strings.ToLower(name)
+	return res.Name == lower ||
+		res.SingularName == lower ||
+		strings.EqualFold(res.Kind, name) ||
+		slices.Contains(res.ShortNames, lower)
+}
+
+// hasSubresource reports whether a group version lists a "resource/subresource".
+func hasSubresource(list *metav1.APIResourceList, name string) bool {
+	return slices.ContainsFunc(list.APIResources, func(res metav1.APIResource) bool {
+		return res.Name == name
+	})
+}
+
 func (e *Extension) handleAuthCanI(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
 	if e.client == nil {
 		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
@@ -17,33 +249,38 @@ func (e *Extension) handleAuthCanI(ctx context.Context, req *sdk.OperationReques
 		return sdk.Failure(fmt.Errorf("args must be an object")), nil
 	}
 
-	verb, _ := args["verb"].(string)
-	resource, _ := args["resource"].(string)
-	as, _ := args["as"].(string)
-	namespace, _ := args["namespace"].(string)
-	apiGroup, _ := args["apiGroup"].(string)
-	resourceName, _ := args["resourceName"].(string)
-
-	if verb == "" {
-		return sdk.Failure(fmt.Errorf("verb is required")), nil
+	access, err := parseAccessSubject(args)
+	if err != nil {
+		return sdk.Failure(err), nil
 	}
-	if resource == "" {
-		return sdk.Failure(fmt.Errorf("resource is required")), nil
+	parseAccessAttributes(&access, args)
+	if err := access.validate(); err != nil {
+		return sdk.Failure(err), nil
 	}
-	if as == "" {
-		return sdk.Failure(fmt.Errorf("as is required")), nil
+
+	if access.NonResourceURL == "" {
+		lists, err := e.kube(ctx).DiscoverResources(ctx)
+		if err != nil {
+			return sdk.Failure(fmt.Errorf("failed to discover API resources: %w", err)), nil
+		}
+		if err := access.resolveResource(ctx, e.kube(ctx), lists); err != nil {
+			return sdk.Failure(err), nil
+		}
 	}
 
 	e.LogInfo(ctx, "Checking permissions", map[string]any{
-		"verb":         verb,
-		"resource":     resource,
-		"as":           as,
-		"namespace":    namespace,
-		"apiGroup":     apiGroup,
-		"resourceName": resourceName,
+		"verb":           access.Verb,
+		"resource":       access.Resource,
+		"subresource":    access.Subresource,
+		"nonResourceURL": access.NonResourceURL,
+		"as":             access.User,
+		"groups":         access.Groups,
+		"namespace":      access.Namespace,
+		"apiGroup":       access.APIGroup,
+		"resourceName":   access.ResourceName,
 	})
 
-	allowed, reason, err := e.client.CheckAccess(ctx, as, verb, resource, apiGroup, namespace, resourceName)
+	allowed, reason, err := e.kube(ctx).CheckAccess(ctx, access)
 	if err != nil {
 		e.LogError(ctx, "Failed to check permissions", map[string]any{
 			"error": err.Error(),
@@ -78,12 +315,7 @@ func (e *Extension) handleAuthCanI(ctx context.Context, req *sdk.OperationReques
 		}
 	}
 
-	msg := fmt.Sprintf("%s can %s %s", as, verb, resource)
-	if namespace != "" {
-		msg += fmt.Sprintf(" in namespace %s", namespace)
-	} else {
-		msg += " cluster-wide"
-	}
+	msg := fmt.Sprintf("%s can %s %s", access.subject(), access.Verb, access.target())
 
 	if allowed {
 		return sdk.SuccessWithOutputs(msg+": allowed", map[string]string{
@@ -3,11 +3,76 @@ package extension
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// accessTestResources is the discovery used by the access review tests. Like
+// the preferred resources of a real cluster, it leaves out subresources.
+var accessTestResources = []*metav1.APIResourceList{
+	{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true, ShortNames: []string{"po"}},
+			{Name: "namespaces", SingularName: "namespace", Kind: "Namespace", ShortNames: []string{"ns"}},
+			{Name: "secrets", SingularName: "secret", Kind: "Secret", Namespaced: true},
+			{Name: "events", SingularName: "event", Kind: "Event", Namespaced: true, ShortNames: []string{"ev"}},
+		},
+	},
+	{
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true, ShortNames: []string{"deploy"}},
+		},
+	},
+	{
+		GroupVersion: "events.k8s.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "events", SingularName: "event", Kind: "Event", Namespaced: true, ShortNames: []string{"ev"}},
+		},
+	},
+	{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "widgets", SingularName: "widget", Kind: "Widget", Namespaced: true},
+		},
+	},
+	{
+		GroupVersion: "other.example.com/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "widgets", SingularName: "widget", Kind: "Widget", Namespaced: true},
+		},
+	},
+}
+
+// accessTestSubresources are the subresources served per group version.
+var accessTestSubresources = map[string][]metav1.APIResource{
+	"v1": {
+		{Name: "pods/log", Kind: "Pod", Namespaced: true},
+		{Name: "pods/exec", Kind: "PodExecOptions", Namespaced: true},
+	},
+	"apps/v1": {
+		{Name: "deployments/scale", Group: "autoscaling", Version: "v1", Kind: "Scale", Namespaced: true},
+	},
+}
+
+// discoverAccessTestGroupVersion serves a group version of accessTestResources
+// together with its subresources.
+func discoverAccessTestGroupVersion(ctx context.Context, groupVersion string) (*metav1.APIResourceList, error) {
+	for _, list := range accessTestResources {
+		if list.GroupVersion == groupVersion {
+			return &metav1.APIResourceList{
+				GroupVersion: groupVersion,
+				APIResources: append(slices.Clone(list.APIResources), accessTestSubresources[groupVersion]...),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("group version %s not found", groupVersion)
+}
+
 func TestHandleAuthCanI(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -24,7 +89,7 @@ func TestHandleAuthCanI(t *testing.T) {
 				"namespace": "default",
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
 					return true, "allowed by RBAC", nil
 				},
 			},
@@ -39,7 +104,7 @@ func TestHandleAuthCanI(t *testing.T) {
 				"namespace": "default",
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
 					return false, "denied by RBAC", nil
 				},
 			},
@@ -53,8 +118,8 @@ func TestHandleAuthCanI(t *testing.T) {
 				"as":       "admin-user",
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
-					if namespace != "" {
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Namespace != "" {
 						return false, "", fmt.Errorf("expected cluster-wide check")
 					}
 					return true, "", nil
@@ -74,7 +139,7 @@ func TestHandleAuthCanI(t *testing.T) {
 				},
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
 					return true, "", nil
 				},
 			},
@@ -92,7 +157,7 @@ func TestHandleAuthCanI(t *testing.T) {
 				},
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
 					return false, "denied", nil
 				},
 			},
@@ -110,7 +175,7 @@ func TestHandleAuthCanI(t *testing.T) {
 				},
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
 					return false, "denied by policy", nil
 				},
 			},
@@ -135,11 +200,28 @@ func TestHandleAuthCanI(t *testing.T) {
 			wantSuccess: false,
 		},
 		{
-			name: "missing as",
+			name: "self access review without as",
 			args: map[string]any{
 				"verb":     "get",
 				"resource": "pods",
 			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if !req.self() {
+						return false, "", fmt.Errorf("expected self access review, got subject %q", req.subject())
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "uid without subject",
+			args: map[string]any{
+				"verb":     "get",
+				"resource": "pods",
+				"uid":      "1234",
+			},
 			client:      &mockClient{},
 			wantSuccess: false,
 		},
@@ -153,9 +235,9 @@ func TestHandleAuthCanI(t *testing.T) {
 				"apiGroup":  "apps",
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
-					if apiGroup != "apps" {
-						return false, "", fmt.Errorf("expected apiGroup=apps, got %s", apiGroup)
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.APIGroup != "apps" {
+						return false, "", fmt.Errorf("expected apiGroup=apps, got %s", req.APIGroup)
 					}
 					return true, "", nil
 				},
@@ -172,9 +254,9 @@ func TestHandleAuthCanI(t *testing.T) {
 				"resourceName": "my-secret",
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
-					if resourceName != "my-secret" {
-						return false, "", fmt.Errorf("expected resourceName=my-secret, got %s", resourceName)
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.ResourceName != "my-secret" {
+						return false, "", fmt.Errorf("expected resourceName=my-secret, got %s", req.ResourceName)
 					}
 					return true, "", nil
 				},
@@ -190,8 +272,8 @@ func TestHandleAuthCanI(t *testing.T) {
 				"namespace": "create-simple-rbac",
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
-					if user != "system:serviceaccount:create-simple-rbac:reader-sa" {
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.User != "system:serviceaccount:create-simple-rbac:reader-sa" {
 						return false, "", fmt.Errorf("expected full service account name")
 					}
 					return true, "", nil
@@ -199,6 +281,303 @@ func TestHandleAuthCanI(t *testing.T) {
 			},
 			wantSuccess: true,
 		},
+		{
+			name: "groups without user",
+			args: map[string]any{
+				"verb":      "list",
+				"resource":  "pods",
+				"groups":    []any{"system:serviceaccounts:team-a"},
+				"namespace": "team-a",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.User != "" || len(req.Groups) != 1 || req.Groups[0] != "system:serviceaccounts:team-a" {
+						return false, "", fmt.Errorf("unexpected subject %q %v", req.User, req.Groups)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "uid and extra",
+			args: map[string]any{
+				"verb":     "get",
+				"resource": "pods",
+				"as":       "alice",
+				"uid":      "1234",
+				"extra": map[string]any{
+					"scopes": []any{"read", "write"},
+					"tenant": "acme",
+				},
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.UID != "1234" || len(req.Extra["scopes"]) != 2 || req.Extra["tenant"][0] != "acme" {
+						return false, "", fmt.Errorf("unexpected uid %q or extra %v", req.UID, req.Extra)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "invalid extra",
+			args: map[string]any{
+				"verb":     "get",
+				"resource": "pods",
+				"as":       "alice",
+				"extra":    map[string]any{"scopes": 1},
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name: "subresource",
+			args: map[string]any{
+				"verb":        "get",
+				"resource":    "pods",
+				"subresource": "log",
+				"as":          "alice",
+				"namespace":   "default",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Resource != "pods" || req.Subresource != "log" {
+						return false, "", fmt.Errorf("expected pods/log, got %s/%s", req.Resource, req.Subresource)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "non-resource URL",
+			args: map[string]any{
+				"verb":           "get",
+				"nonResourceURL": "/metrics",
+				"as":             "prometheus",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.NonResourceURL != "/metrics" || req.Resource != "" {
+						return false, "", fmt.Errorf("expected non-resource check of /metrics")
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "non-resource URL with resource",
+			args: map[string]any{
+				"verb":           "get",
+				"resource":       "pods",
+				"nonResourceURL": "/metrics",
+				"as":             "prometheus",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name: "non-resource URL without leading slash",
+			args: map[string]any{
+				"verb":           "get",
+				"nonResourceURL": "metrics",
+				"as":             "prometheus",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name: "kind name resolves to plural",
+			args: map[string]any{
+				"verb":     "get",
+				"as":       "alice",
+				"resource": "Pod",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Resource != "pods" || req.APIGroup != "" || req.Subresource != "" {
+						return false, "", fmt.Errorf("unexpected resource %s/%s in group %q", req.Resource, req.Subresource, req.APIGroup)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "short name infers api group",
+			args: map[string]any{
+				"verb":     "get",
+				"as":       "alice",
+				"resource": "deploy",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Resource != "deployments" || req.APIGroup != "apps" || req.Subresource != "" {
+						return false, "", fmt.Errorf("unexpected resource %s/%s in group %q", req.Resource, req.Subresource, req.APIGroup)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "singular name infers api group",
+			args: map[string]any{
+				"verb":     "get",
+				"as":       "alice",
+				"resource": "deployment",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Resource != "deployments" || req.APIGroup != "apps" || req.Subresource != "" {
+						return false, "", fmt.Errorf("unexpected resource %s/%s in group %q", req.Resource, req.Subresource, req.APIGroup)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "resource with group suffix",
+			args: map[string]any{
+				"verb":     "get",
+				"as":       "alice",
+				"resource": "events.events.k8s.io",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Resource != "events" || req.APIGroup != "events.k8s.io" || req.Subresource != "" {
+						return false, "", fmt.Errorf("unexpected resource %s/%s in group %q", req.Resource, req.Subresource, req.APIGroup)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "core group preferred when ambiguous",
+			args: map[string]any{
+				"verb":     "get",
+				"as":       "alice",
+				"resource": "events",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Resource != "events" || req.APIGroup != "" || req.Subresource != "" {
+						return false, "", fmt.Errorf("unexpected resource %s/%s in group %q", req.Resource, req.Subresource, req.APIGroup)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "split subresource",
+			args: map[string]any{
+				"verb":     "get",
+				"as":       "alice",
+				"resource": "pods/log",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Resource != "pods" || req.APIGroup != "" || req.Subresource != "log" {
+						return false, "", fmt.Errorf("unexpected resource %s/%s in group %q", req.Resource, req.Subresource, req.APIGroup)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "subresource in a named group",
+			args: map[string]any{
+				"verb":        "update",
+				"as":          "alice",
+				"resource":    "deployments.apps",
+				"subresource": "scale",
+			},
+			client: &mockClient{
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
+					if req.Resource != "deployments" || req.APIGroup != "apps" || req.Subresource != "scale" {
+						return false, "", fmt.Errorf("unexpected resource %s/%s in group %q", req.Resource, req.Subresource, req.APIGroup)
+					}
+					return true, "", nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "subresource discovery error",
+			args: map[string]any{
+				"verb":     "get",
+				"as":       "alice",
+				"resource": "pods/log",
+			},
+			client: &mockClient{
+				discoverGroupVersionFn: func(ctx context.Context, groupVersion string) (*metav1.APIResourceList, error) {
+					return nil, fmt.Errorf("connection refused")
+				},
+			},
+			wantSuccess: false,
+		},
+		{
+			name: "unknown resource",
+			args: map[string]any{
+				"verb":     "get",
+				"resource": "pdos",
+				"as":       "alice",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name: "resource not in api group",
+			args: map[string]any{
+				"verb":     "get",
+				"resource": "deployments",
+				"apiGroup": "extensions",
+				"as":       "alice",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name: "ambiguous api group",
+			args: map[string]any{
+				"verb":     "get",
+				"resource": "widgets",
+				"as":       "alice",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name: "unknown subresource",
+			args: map[string]any{
+				"verb":     "get",
+				"resource": "pods/logs",
+				"as":       "alice",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name: "discovery error",
+			args: map[string]any{
+				"verb":     "get",
+				"resource": "pods",
+				"as":       "alice",
+			},
+			client: &mockClient{
+				discoverResourcesFn: func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+					return nil, fmt.Errorf("connection refused")
+				},
+			},
+			wantSuccess: false,
+		},
 		{
 			name: "client error",
 			args: map[string]any{
@@ -208,7 +587,7 @@ func TestHandleAuthCanI(t *testing.T) {
 				"namespace": "default",
 			},
 			client: &mockClient{
-				checkAccessFn: func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
+				checkAccessFn: func(ctx context.Context, req AccessRequest) (bool, string, error) {
 					return false, "", fmt.Errorf("connection refused")
 				},
 			},
@@ -218,6 +597,14 @@ func TestHandleAuthCanI(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			if tt.client.discoverResourcesFn == nil {
+				tt.client.discoverResourcesFn = func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+					return accessTestResources, nil
+				}
+			}
+			if tt.client.discoverGroupVersionFn == nil {
+				tt.client.discoverGroupVersionFn = discoverAccessTestGroupVersion
+			}
 			ext := &Extension{
 				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
 				client:    tt.client,
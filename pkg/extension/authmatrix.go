@@ -0,0 +1,174 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+)
+
+// defaultAccessCheckConcurrency is the number of SubjectAccessReviews run in parallel.
+const defaultAccessCheckConcurrency = 10
+
+// accessCheck is a row of an authCanIMatrix: a permission and its expected result.
+type accessCheck struct {
+	AccessRequest
+	expected bool
+
+	allowed bool
+	reason  string
+	err     error
+}
+
+// parseAccessChecks reads the checks argument. Every row is checked for
+// subject; rows without a namespace use the namespace of subject.
+func parseAccessChecks(args map[string]any, subject AccessRequest) ([]accessCheck, error) {
+	rows, ok := args["checks"].([]any)
+	if !ok || len(rows) == 0 {
+		return nil, fmt.Errorf("checks must be a non-empty list")
+	}
+
+	checks := make([]accessCheck, 0, len(rows))
+	for i, row := range rows {
+		m, ok := row.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("checks[%d] must be an object", i)
+		}
+
+		c := accessCheck{AccessRequest: subject}
+		parseAccessAttributes(&c.AccessRequest, m)
+		if c.NonResourceURL != "" {
+			// Non-resource URLs are never namespaced, so the default does not apply.
+			if _, ok := m["namespace"]; !ok {
+				c.Namespace = ""
+			}
+		}
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("checks[%d]: %w", i, err)
+		}
+
+		expected, ok := m["allowed"].(bool)
+		if !ok {
+			return nil, fmt.Errorf("checks[%d].allowed must be a boolean", i)
+		}
+		c.expected = expected
+
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// handleAuthCanIMatrix runs a list of permission checks for one subject
+// concurrently and reports every check whose result differs from the expectation.
+func (e *Extension) handleAuthCanIMatrix(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	subject, err := parseAccessSubject(args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	subject.Namespace, _ = args["namespace"].(string)
+
+	as := subject.subject()
+	checks, err := parseAccessChecks(args, subject)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	lists, err := e.kube(ctx).DiscoverResources(ctx)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to discover API resources: %w", err)), nil
+	}
+	for i := range checks {
+		if err := checks[i].resolveResource(ctx, e.kube(ctx), lists); err != nil {
+			return sdk.Failure(fmt.Errorf("checks[%d]: %w", i, err)), nil
+		}
+	}
+
+	concurrency, hasConcurrency, err := intArg(args, "concurrency")
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	if !hasConcurrency {
+		concurrency = defaultAccessCheckConcurrency
+	}
+	if concurrency < 1 {
+		return sdk.Failure(fmt.Errorf("concurrency must be at least 1")), nil
+	}
+
+	e.LogInfo(ctx, "Checking permission matrix", map[string]any{
+		"as":     as,
+		"checks": len(checks),
+	})
+
+	forEachParallel(len(checks), concurrency, func(i int) {
+		c := &checks[i]
+		c.allowed, c.reason, c.err = e.kube(ctx).CheckAccess(ctx, c.AccessRequest)
+	})
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERB\tRESOURCE\tEXPECTED\tACTUAL\tREASON")
+
+	mismatches, failures := 0, 0
+	for _, c := range checks {
+		switch {
+		case c.err != nil:
+			failures++
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Verb, c.target(), allowedString(c.expected), "error", c.err.Error())
+		case c.allowed != c.expected:
+			mismatches++
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Verb, c.target(), allowedString(c.expected), allowedString(c.allowed), c.reason)
+		}
+	}
+	w.Flush()
+
+	outputs := map[string]string{
+		"total":      fmt.Sprintf("%d", len(checks)),
+		"passed":     fmt.Sprintf("%d", len(checks)-mismatches-failures),
+		"mismatches": fmt.Sprintf("%d", mismatches),
+		"errors":     fmt.Sprintf("%d", failures),
+		"table":      "",
+	}
+
+	if mismatches > 0 || failures > 0 {
+		outputs["table"] = table.String()
+		e.LogError(ctx, "Permission matrix mismatches", map[string]any{
+			"as":         as,
+			"mismatches": mismatches,
+			"errors":     failures,
+		})
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("%d of %d permission check(s) for %s did not match:\n%s", mismatches+failures, len(checks), as, table.String()),
+			Error:   "permission expectations not met",
+			Outputs: outputs,
+		}, nil
+	}
+
+	e.LogInfo(ctx, "Permission matrix matched", map[string]any{
+		"as":     as,
+		"checks": len(checks),
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("All %d permission check(s) for %s matched", len(checks), as),
+		outputs,
+	), nil
+}
+
+func allowedString(allowed bool) string {
+	if allowed {
+		return "allowed"
+	}
+	return "denied"
+}
@@ -0,0 +1,141 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandleAuthCanIMatrix(t *testing.T) {
+	// Subject can read pods everywhere and delete them only in team-a.
+	checkAccess := func(ctx context.Context, req AccessRequest) (bool, string, error) {
+		switch {
+		case req.Resource == "secrets":
+			return false, "", fmt.Errorf("connection refused")
+		case req.Verb == "get" || req.Verb == "list":
+			return true, "allowed by role reader", nil
+		case req.Verb == "delete" && req.Namespace == "team-a":
+			return true, "allowed by role editor", nil
+		}
+		return false, "no matching rule", nil
+	}
+
+	tests := []struct {
+		name           string
+		args           any
+		clientNil      bool
+		wantSuccess    bool
+		wantErrContain string
+		wantOutputs    map[string]string
+		wantTable      []string
+	}{
+		{
+			name:           "client not initialized",
+			args:           map[string]any{"as": "alice"},
+			clientNil:      true,
+			wantErrContain: "kubernetes client not initialized",
+		},
+		{
+			name:           "empty checks",
+			args:           map[string]any{"as": "alice", "checks": []any{}},
+			wantErrContain: "checks must be a non-empty list",
+		},
+		{
+			name: "missing expectation",
+			args: map[string]any{
+				"as":     "alice",
+				"checks": []any{map[string]any{"verb": "get", "resource": "pods"}},
+			},
+			wantErrContain: "checks[0].allowed must be a boolean",
+		},
+		{
+			name: "unknown resource",
+			args: map[string]any{
+				"as":     "alice",
+				"checks": []any{map[string]any{"verb": "get", "resource": "pod", "allowed": true}, map[string]any{"verb": "get", "resource": "podz", "allowed": true}},
+			},
+			wantErrContain: `checks[1]: unknown resource "podz"`,
+		},
+		{
+			name: "all checks match",
+			args: map[string]any{
+				"as":        "alice",
+				"namespace": "team-a",
+				"checks": []any{
+					map[string]any{"verb": "get", "resource": "pods", "allowed": true},
+					map[string]any{"verb": "delete", "resource": "pods", "allowed": true},
+					map[string]any{"verb": "delete", "resource": "pods", "namespace": "team-b", "allowed": false},
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{"total": "3", "passed": "3", "mismatches": "0", "errors": "0", "table": ""},
+		},
+		{
+			name: "mismatches and errors",
+			args: map[string]any{
+				"as":          "alice",
+				"concurrency": 2,
+				"checks": []any{
+					map[string]any{"verb": "list", "resource": "pods", "namespace": "team-a", "allowed": true},
+					map[string]any{"verb": "delete", "resource": "pods", "namespace": "team-b", "allowed": true},
+					map[string]any{"verb": "get", "resource": "deployments", "apiGroup": "apps", "resourceName": "web", "namespace": "team-b", "allowed": false},
+					map[string]any{"verb": "get", "resource": "secrets", "allowed": false},
+				},
+			},
+			wantErrContain: "permission expectations not met",
+			wantOutputs:    map[string]string{"total": "4", "passed": "1", "mismatches": "2", "errors": "1"},
+			wantTable: []string{
+				"pods in namespace team-b",
+				"deployments.apps/web in namespace team-b",
+				"secrets cluster-wide",
+				"connection refused",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client: &mockClient{
+					checkAccessFn: checkAccess,
+					discoverResourcesFn: func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+						return accessTestResources, nil
+					},
+					discoverGroupVersionFn: discoverAccessTestGroupVersion,
+				},
+			}
+			if tt.clientNil {
+				ext.client = nil
+			}
+
+			result, err := ext.handleAuthCanIMatrix(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("output %s = %q, want %q", k, got, want)
+				}
+			}
+			for _, want := range tt.wantTable {
+				if !strings.Contains(result.Outputs["table"], want) {
+					t.Errorf("expected table to contain %q, got:\n%s", want, result.Outputs["table"])
+				}
+			}
+			if strings.Contains(result.Outputs["table"], "pods in namespace team-a") {
+				t.Errorf("matching checks should not be in the table:\n%s", result.Outputs["table"])
+			}
+		})
+	}
+}
@@ -2,15 +2,25 @@ package extension
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
@@ -24,30 +34,146 @@ type ResourceClient interface {
 	// Get retrieves a Kubernetes resource by name.
 	Get(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error)
 
+	// List retrieves Kubernetes resources of a type. An empty namespace lists across all namespaces.
+	List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
+
+	// Update replaces a Kubernetes resource, or one of its subresources (e.g. "finalize").
+	Update(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string, subresources ...string) (*unstructured.Unstructured, error)
+
+	// Patch applies a patch of the given type to a Kubernetes resource.
+	Patch(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error)
+
 	// Delete removes a Kubernetes resource.
 	Delete(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error
 
-	// CheckAccess checks if a user can perform an action on a resource.
-	CheckAccess(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error)
+	// DeleteCollection removes all Kubernetes resources of a type matching the list options.
+	DeleteCollection(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+
+	// CheckAccess checks if a subject can perform an action on a resource or non-resource URL.
+	// Returns whether the action is allowed and the reason reported by the authorizer.
+	// A request without user and groups checks the client's own identity.
+	CheckAccess(ctx context.Context, req AccessRequest) (bool, string, error)
+
+	// ListRules returns the rules the client's identity is granted in a namespace.
+	ListRules(ctx context.Context, namespace string) (*authorizationv1.SubjectRulesReviewStatus, error)
+
+	// Impersonate returns a client that sends every request as the given subject.
+	// Clients are cached per subject.
+	Impersonate(impersonate rest.ImpersonationConfig) (ResourceClient, error)
+
+	// CreateToken requests a token for a ServiceAccount through the TokenRequest API.
+	// The token expires after expirationSeconds and is bound to the given audiences,
+	// or the API server's audience when none are given.
+	CreateToken(ctx context.Context, namespace, serviceAccount string, expirationSeconds int64, audiences []string) (*authenticationv1.TokenRequestStatus, error)
 
-	// ListContexts returns all contexts from the kubeconfig sorted by name.
+	// DiscoverResources returns the preferred version of every resource type served by the cluster.
+	// Partial results are returned when only some API groups fail discovery.
+	DiscoverResources(ctx context.Context) ([]*metav1.APIResourceList, error)
+
+	// DiscoverGroupVersion returns every resource type served in a group version,
+	// including subresources such as "pods/log" that DiscoverResources leaves out.
+	DiscoverGroupVersion(ctx context.Context, groupVersion string) (*metav1.APIResourceList, error)
+
+	// ListContexts returns all contexts from the merged kubeconfig sorted by name.
 	// Each context includes its name, cluster, user, namespace, and whether it's the current context.
 	ListContexts(ctx context.Context) ([]ContextInfo, error)
 
-	// GetCurrentContext returns the current context name from the kubeconfig.
+	// GetCurrentContext returns the current context name from the merged kubeconfig.
 	// Returns an error if the kubeconfig cannot be loaded.
 	GetCurrentContext(ctx context.Context) (string, error)
 
-	// ViewConfig returns the kubeconfig as YAML.
-	// When minify is true, only the current context and its dependencies are included.
+	// ViewConfig returns the merged kubeconfig as YAML.
+	// When minify is true, only the client's context and its dependencies are included,
+	// and that context is made the current context.
 	ViewConfig(ctx context.Context, minify bool) (string, error)
 }
 
 // dynamicClientAdapter adapts the Kubernetes dynamic client to the ResourceClient interface.
 type dynamicClientAdapter struct {
-	client       dynamic.Interface
-	authzClient  authorizationv1client.AuthorizationV1Interface
-	kubeconfigPath string
+	client          dynamic.Interface
+	authzClient     authorizationv1client.AuthorizationV1Interface
+	coreClient      corev1client.CoreV1Interface
+	discoveryClient discovery.DiscoveryInterface
+	restConfig      *rest.Config
+	// loadingRules locate the kubeconfig files the client was built from,
+	// or are nil when it uses the in-cluster service account configuration.
+	loadingRules *clientcmd.ClientConfigLoadingRules
+	// contextName is the kubeconfig context the client was built for, or
+	// empty for the current context.
+	contextName string
+
+	impersonatedMu sync.Mutex
+	impersonated   map[string]*dynamicClientAdapter
+}
+
+// inClusterContext names the in-cluster service account configuration when it
+// is presented as a kubeconfig.
+const inClusterContext = "in-cluster"
+
+// newContextClientAdapter creates the clients for a context of the merged
+// kubeconfig located by loadingRules. An empty context name selects the
+// current context. Without loading rules, the in-cluster service account
+// configuration is used.
+func newContextClientAdapter(loadingRules *clientcmd.ClientConfigLoadingRules, contextName string) (*dynamicClientAdapter, error) {
+	var config *rest.Config
+	var err error
+	if loadingRules == nil {
+		if contextName != "" && contextName != inClusterContext {
+			return nil, fmt.Errorf("context %q not found: no kubeconfig is loaded, using the in-cluster configuration", contextName)
+		}
+		config, err = rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("no kubeconfig found and in-cluster configuration is not available: %w", err)
+		}
+	} else {
+		clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			loadingRules,
+			&clientcmd.ConfigOverrides{CurrentContext: contextName},
+		)
+		config, err = clientConfig.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kubeconfig from %s: %w", strings.Join(loadingRules.GetLoadingPrecedence(), string(os.PathListSeparator)), err)
+		}
+	}
+
+	client, err := newDynamicClientAdapter(config, loadingRules)
+	if err != nil {
+		return nil, err
+	}
+	client.contextName = contextName
+	return client, nil
+}
+
+// newDynamicClientAdapter creates the clients for a rest.Config.
+func newDynamicClientAdapter(config *rest.Config, loadingRules *clientcmd.ClientConfigLoadingRules) (*dynamicClientAdapter, error) {
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	authzClient, err := authorizationv1client.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorization client: %w", err)
+	}
+
+	coreClient, err := corev1client.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create core client: %w", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	return &dynamicClientAdapter{
+		client:          client,
+		authzClient:     authzClient,
+		coreClient:      coreClient,
+		discoveryClient: discoveryClient,
+		restConfig:      config,
+		loadingRules:    loadingRules,
+	}, nil
 }
 
 func (a *dynamicClientAdapter) Create(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
@@ -64,6 +190,27 @@ func (a *dynamicClientAdapter) Get(ctx context.Context, gvr schema.GroupVersionR
 	return a.client.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
 }
 
+func (a *dynamicClientAdapter) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	if namespace != "" {
+		return a.client.Resource(gvr).Namespace(namespace).List(ctx, opts)
+	}
+	return a.client.Resource(gvr).List(ctx, opts)
+}
+
+func (a *dynamicClientAdapter) Update(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string, subresources ...string) (*unstructured.Unstructured, error) {
+	if namespace != "" {
+		return a.client.Resource(gvr).Namespace(namespace).Update(ctx, obj, metav1.UpdateOptions{}, subresources...)
+	}
+	return a.client.Resource(gvr).Update(ctx, obj, metav1.UpdateOptions{}, subresources...)
+}
+
+func (a *dynamicClientAdapter) Patch(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	if namespace != "" {
+		return a.client.Resource(gvr).Namespace(namespace).Patch(ctx, name, patchType, data, metav1.PatchOptions{})
+	}
+	return a.client.Resource(gvr).Patch(ctx, name, patchType, data, metav1.PatchOptions{})
+}
+
 func (a *dynamicClientAdapter) Delete(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
 	if namespace != "" {
 		return a.client.Resource(gvr).Namespace(namespace).Delete(ctx, name, opts)
@@ -71,17 +218,59 @@ func (a *dynamicClientAdapter) Delete(ctx context.Context, gvr schema.GroupVersi
 	return a.client.Resource(gvr).Delete(ctx, name, opts)
 }
 
-func (a *dynamicClientAdapter) CheckAccess(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
+func (a *dynamicClientAdapter) DeleteCollection(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	if namespace != "" {
+		return a.client.Resource(gvr).Namespace(namespace).DeleteCollection(ctx, opts, listOpts)
+	}
+	return a.client.Resource(gvr).DeleteCollection(ctx, opts, listOpts)
+}
+
+func (a *dynamicClientAdapter) CheckAccess(ctx context.Context, req AccessRequest) (bool, string, error) {
+	var resourceAttrs *authorizationv1.ResourceAttributes
+	var nonResourceAttrs *authorizationv1.NonResourceAttributes
+	if req.NonResourceURL != "" {
+		nonResourceAttrs = &authorizationv1.NonResourceAttributes{
+			Verb: req.Verb,
+			Path: req.NonResourceURL,
+		}
+	} else {
+		resourceAttrs = &authorizationv1.ResourceAttributes{
+			Verb:        req.Verb,
+			Resource:    req.Resource,
+			Subresource: req.Subresource,
+			Group:       req.APIGroup,
+			Namespace:   req.Namespace,
+			Name:        req.ResourceName,
+		}
+	}
+
+	if req.self() {
+		ssar := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes:    resourceAttrs,
+				NonResourceAttributes: nonResourceAttrs,
+			},
+		}
+		result, err := a.authzClient.SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
+		if err != nil {
+			return false, "", err
+		}
+		return result.Status.Allowed, result.Status.Reason, nil
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.Extra))
+	for k, v := range req.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
 	sar := &authorizationv1.SubjectAccessReview{
 		Spec: authorizationv1.SubjectAccessReviewSpec{
-			User: user,
-			ResourceAttributes: &authorizationv1.ResourceAttributes{
-				Verb:      verb,
-				Resource:  resource,
-				Group:     apiGroup,
-				Namespace: namespace,
-				Name:      resourceName,
-			},
+			User:                  req.User,
+			Groups:                req.Groups,
+			UID:                   req.UID,
+			Extra:                 extra,
+			ResourceAttributes:    resourceAttrs,
+			NonResourceAttributes: nonResourceAttrs,
 		},
 	}
 
@@ -93,8 +282,112 @@ func (a *dynamicClientAdapter) CheckAccess(ctx context.Context, user, verb, reso
 	return result.Status.Allowed, result.Status.Reason, nil
 }
 
+func (a *dynamicClientAdapter) ListRules(ctx context.Context, namespace string) (*authorizationv1.SubjectRulesReviewStatus, error) {
+	review := &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}
+	result, err := a.authzClient.SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Status, nil
+}
+
+func (a *dynamicClientAdapter) Impersonate(impersonate rest.ImpersonationConfig) (ResourceClient, error) {
+	groups := slices.Clone(impersonate.Groups)
+	sort.Strings(groups)
+	key := impersonate.UserName + "\x00" + impersonate.UID + "\x00" + strings.Join(groups, "\x00")
+	if len(impersonate.Extra) > 0 {
+		extra, err := json.Marshal(impersonate.Extra)
+		if err != nil {
+			return nil, err
+		}
+		key += "\x00" + string(extra)
+	}
+
+	a.impersonatedMu.Lock()
+	defer a.impersonatedMu.Unlock()
+
+	if client, ok := a.impersonated[key]; ok {
+		return client, nil
+	}
+
+	config := rest.CopyConfig(a.restConfig)
+	config.Impersonate = impersonate
+	client, err := newDynamicClientAdapter(config, a.loadingRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client impersonating %s: %w", impersonate.UserName, err)
+	}
+	client.contextName = a.contextName
+
+	if a.impersonated == nil {
+		a.impersonated = make(map[string]*dynamicClientAdapter)
+	}
+	a.impersonated[key] = client
+	return client, nil
+}
+
+func (a *dynamicClientAdapter) CreateToken(ctx context.Context, namespace, serviceAccount string, expirationSeconds int64, audiences []string) (*authenticationv1.TokenRequestStatus, error) {
+	tr := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         audiences,
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	result, err := a.coreClient.ServiceAccounts(namespace).CreateToken(ctx, serviceAccount, tr, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Status, nil
+}
+
+func (a *dynamicClientAdapter) DiscoverResources(ctx context.Context) ([]*metav1.APIResourceList, error) {
+	lists, err := a.discoveryClient.ServerPreferredResources()
+	if err != nil && !(discovery.IsGroupDiscoveryFailedError(err) && len(lists) > 0) {
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (a *dynamicClientAdapter) DiscoverGroupVersion(ctx context.Context, groupVersion string) (*metav1.APIResourceList, error) {
+	return a.discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+}
+
+// rawConfig returns the merged kubeconfig, or a kubeconfig describing the
+// in-cluster configuration when no kubeconfig file is loaded.
+func (a *dynamicClientAdapter) rawConfig() (*clientcmdapi.Config, error) {
+	if a.loadingRules == nil {
+		return inClusterRawConfig(a.restConfig), nil
+	}
+	return a.loadingRules.Load()
+}
+
+// inClusterRawConfig presents the in-cluster service account configuration as
+// a kubeconfig with a single in-cluster context.
+func inClusterRawConfig(config *rest.Config) *clientcmdapi.Config {
+	raw := clientcmdapi.NewConfig()
+	raw.Clusters[inClusterContext] = &clientcmdapi.Cluster{
+		Server:                   config.Host,
+		CertificateAuthority:     config.TLSClientConfig.CAFile,
+		CertificateAuthorityData: config.TLSClientConfig.CAData,
+	}
+	raw.AuthInfos[inClusterContext] = &clientcmdapi.AuthInfo{
+		Token:     config.BearerToken,
+		TokenFile: config.BearerTokenFile,
+	}
+	raw.Contexts[inClusterContext] = &clientcmdapi.Context{
+		Cluster:  inClusterContext,
+		AuthInfo: inClusterContext,
+	}
+	if ns, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
+		raw.Contexts[inClusterContext].Namespace = strings.TrimSpace(string(ns))
+	}
+	raw.CurrentContext = inClusterContext
+	return raw
+}
+
 func (a *dynamicClientAdapter) ListContexts(ctx context.Context) ([]ContextInfo, error) {
-	config, err := clientcmd.LoadFromFile(a.kubeconfigPath)
+	config, err := a.rawConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
@@ -119,7 +412,7 @@ func (a *dynamicClientAdapter) ListContexts(ctx context.Context) ([]ContextInfo,
 }
 
 func (a *dynamicClientAdapter) GetCurrentContext(ctx context.Context) (string, error) {
-	config, err := clientcmd.LoadFromFile(a.kubeconfigPath)
+	config, err := a.rawConfig()
 	if err != nil {
 		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
@@ -128,16 +421,19 @@ func (a *dynamicClientAdapter) GetCurrentContext(ctx context.Context) (string, e
 }
 
 func (a *dynamicClientAdapter) ViewConfig(ctx context.Context, minify bool) (string, error) {
-	// Load the full config
-	rawConfig, err := clientcmd.LoadFromFile(a.kubeconfigPath)
+	// Load the full, merged config
+	rawConfig, err := a.rawConfig()
 	if err != nil {
 		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
 	// Apply minification if requested
 	if minify {
-		// Get current context
+		// Get the client's context, or the current context
 		currentContext := rawConfig.CurrentContext
+		if a.contextName != "" {
+			currentContext = a.contextName
+		}
 		if currentContext == "" {
 			return "", fmt.Errorf("no current context set in kubeconfig")
 		}
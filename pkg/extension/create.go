@@ -3,6 +3,7 @@ package extension
 import (
 	"context"
 	"fmt"
+	"maps"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -14,11 +15,23 @@ func (e *Extension) handleCreate(ctx context.Context, req *sdk.OperationRequest)
 	}
 
 	// Args is the resource spec as a map
-	resourceSpec, ok := req.Args.(map[string]any)
+	args, ok := req.Args.(map[string]any)
 	if !ok {
 		return sdk.Failure(fmt.Errorf("args must be a resource spec object")), nil
 	}
 
+	actor, err := e.actorFor(ctx, args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	// The impersonation and context arguments sit next to the resource spec and must not be sent to the API server.
+	resourceSpec := maps.Clone(args)
+	for _, key := range impersonationKeys {
+		delete(resourceSpec, key)
+	}
+	delete(resourceSpec, "context")
+
 	obj := &unstructured.Unstructured{Object: resourceSpec}
 
 	gvk := obj.GroupVersionKind()
@@ -28,14 +41,22 @@ func (e *Extension) handleCreate(ctx context.Context, req *sdk.OperationRequest)
 
 	gvr := gvkToGVR(gvk)
 	namespace := obj.GetNamespace()
+	e.applyRunLabels(obj)
 
 	e.LogInfo(ctx, "Creating resource", map[string]any{
 		"kind":      gvk.Kind,
 		"name":      obj.GetName(),
 		"namespace": namespace,
+		"as":        actor.as,
 	})
 
-	result, err := e.client.Create(ctx, gvr, obj, namespace)
+	result, err := actor.client.Create(ctx, gvr, obj, namespace)
+	if err == nil {
+		e.trackResource(ctx, gvr, result)
+	}
+	if r := actor.forbidden(err, fmt.Sprintf("create %s/%s", gvk.Kind, obj.GetName())); r != nil {
+		return r, nil
+	}
 	if err != nil {
 		e.LogError(ctx, "Failed to create resource", map[string]any{
 			"kind":  gvk.Kind,
@@ -2,11 +2,18 @@ package extension
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func (e *Extension) handleDelete(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
@@ -19,12 +26,48 @@ func (e *Extension) handleDelete(ctx context.Context, req *sdk.OperationRequest)
 		return sdk.Failure(fmt.Errorf("args must be an object")), nil
 	}
 
+	deleteOpts, err := parseDeleteOptions(args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	actor, err := e.actorFor(ctx, args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	dryRun := len(deleteOpts.DryRun) > 0
+
+	labelSelector, _ := args["labelSelector"].(string)
+	fieldSelector, _ := args["fieldSelector"].(string)
+	if labelSelector != "" || fieldSelector != "" {
+		if deleteOpts.Preconditions != nil {
+			return sdk.Failure(fmt.Errorf("preconditions cannot be combined with labelSelector or fieldSelector")), nil
+		}
+		return e.deleteBySelector(ctx, actor, args, deleteOpts, metav1.ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector}), nil
+	}
+
 	ref, err := parseResourceRef(args)
 	if err != nil {
 		return sdk.Failure(err), nil
 	}
 
 	ignoreNotFound, _ := args["ignoreNotFound"].(bool)
+	force, _ := args["force"].(bool)
+
+	terminationTimeout := defaultTerminationTimeout
+	if timeoutStr, _ := args["terminationTimeout"].(string); timeoutStr != "" {
+		terminationTimeout, err = time.ParseDuration(timeoutStr)
+		if err != nil {
+			return sdk.Failure(fmt.Errorf("invalid terminationTimeout format: %w", err)), nil
+		}
+	}
+
+	// Removing finalizers must not remove the foregroundDeletion finalizer
+	// that a Foreground delete relies on to delete dependents first, so a
+	// forced delete propagates in the background unless told otherwise.
+	if _, ok := args["propagationPolicy"]; force && !ok {
+		background := metav1.DeletePropagationBackground
+		deleteOpts.PropagationPolicy = &background
+	}
 
 	gvr, err := ref.gvr()
 	if err != nil {
@@ -36,14 +79,16 @@ func (e *Extension) handleDelete(ctx context.Context, req *sdk.OperationRequest)
 		"name":           ref.name,
 		"namespace":      ref.namespace,
 		"ignoreNotFound": ignoreNotFound,
+		"force":          force,
+		"propagation":    *deleteOpts.PropagationPolicy,
+		"dryRun":         dryRun,
+		"as":             actor.as,
 	})
 
-	propagation := metav1.DeletePropagationForeground
-	deleteOpts := metav1.DeleteOptions{
-		PropagationPolicy: &propagation,
+	err = actor.client.Delete(ctx, gvr, ref.name, ref.namespace, deleteOpts)
+	if r := actor.forbidden(err, fmt.Sprintf("delete %s/%s", ref.kind, ref.name)); r != nil {
+		return r, nil
 	}
-
-	err = e.client.Delete(ctx, gvr, ref.name, ref.namespace, deleteOpts)
 	if err != nil {
 		if ignoreNotFound && apierrors.IsNotFound(err) {
 			e.LogInfo(ctx, "Resource not found (ignored)", map[string]any{
@@ -52,6 +97,9 @@ func (e *Extension) handleDelete(ctx context.Context, req *sdk.OperationRequest)
 			})
 			return sdk.Success(fmt.Sprintf("%s/%s not found (ignored)", ref.kind, ref.name)), nil
 		}
+		if apierrors.IsConflict(err) && deleteOpts.Preconditions != nil {
+			return sdk.Failure(fmt.Errorf("precondition failed for %s/%s: %w", ref.kind, ref.name, err)), nil
+		}
 		e.LogError(ctx, "Failed to delete resource", map[string]any{
 			"kind":  ref.kind,
 			"name":  ref.name,
@@ -65,5 +113,349 @@ func (e *Extension) handleDelete(ctx context.Context, req *sdk.OperationRequest)
 		"name": ref.name,
 	})
 
+	if dryRun {
+		return sdk.Success(fmt.Sprintf("Deleted %s/%s (dry run)", ref.kind, ref.name)), nil
+	}
+
+	if gvr == namespaceGVR {
+		// Without force, the namespace is inspected once rather than waited on.
+		var settle time.Duration
+		if force {
+			settle = terminationTimeout
+		}
+		return e.namespaceDeleteResult(ctx, ref.name, force, settle), nil
+	}
+
+	if force {
+		return e.forceDeleteResult(ctx, gvr, ref, terminationTimeout), nil
+	}
+
 	return sdk.Success(fmt.Sprintf("Deleted %s/%s", ref.kind, ref.name)), nil
 }
+
+// forceDeleteResult gives a deleted object up to settle to go away and then
+// removes the finalizers that still keep it. The foregroundDeletion and orphan
+// finalizers drive the garbage collection of the object's dependents and are kept.
+func (e *Extension) forceDeleteResult(ctx context.Context, gvr schema.GroupVersionResource, ref *resourceRef, settle time.Duration) *sdk.OperationResult {
+	deadline := time.Now().Add(settle)
+	var obj *unstructured.Unstructured
+	for {
+		var err error
+		obj, err = e.kube(ctx).Get(ctx, gvr, ref.name, ref.namespace)
+		if apierrors.IsNotFound(err) {
+			return sdk.Success(fmt.Sprintf("Deleted %s/%s", ref.kind, ref.name))
+		}
+		if err != nil {
+			return sdk.Failure(fmt.Errorf("failed to get resource after deletion: %w", err))
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return sdk.Failure(fmt.Errorf("%s/%s is still terminating: %w", ref.kind, ref.name, ctx.Err()))
+		case <-time.After(time.Second):
+		}
+	}
+
+	var kept, removed []string
+	for _, f := range obj.GetFinalizers() {
+		if f == metav1.FinalizerDeleteDependents || f == metav1.FinalizerOrphanDependents {
+			kept = append(kept, f)
+		} else {
+			removed = append(removed, f)
+		}
+	}
+	if len(removed) == 0 {
+		return sdk.Success(fmt.Sprintf("Deleted %s/%s (still terminating)", ref.kind, ref.name))
+	}
+
+	e.LogWarn(ctx, "Force-removing finalizers from terminating resource", map[string]any{
+		"kind":       ref.kind,
+		"name":       ref.name,
+		"finalizers": removed,
+	})
+
+	// The resourceVersion makes the patch fail rather than drop a finalizer
+	// that was changed in the meantime.
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"finalizers":      append([]string{}, kept...),
+			"resourceVersion": obj.GetResourceVersion(),
+		},
+	})
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to build finalizer patch: %w", err))
+	}
+	_, err = e.kube(ctx).Patch(ctx, gvr, ref.name, ref.namespace, types.MergePatchType, patch)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return sdk.Failure(fmt.Errorf("failed to remove finalizers: %w", err))
+	}
+	return sdk.Success(fmt.Sprintf("Deleted %s/%s (removed finalizers: %s)", ref.kind, ref.name, strings.Join(removed, ", ")))
+}
+
+// namespaceDeleteResult reports whether a deleted namespace is gone or still
+// terminating, listing the objects that block it. With force, the finalizers
+// of the remaining objects and of the namespace are removed first once the
+// namespace is stuck.
+func (e *Extension) namespaceDeleteResult(ctx context.Context, name string, force bool, settle time.Duration) *sdk.OperationResult {
+	t, err := e.checkNamespaceTermination(ctx, name, force, false, settle)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to check namespace termination: %w", err))
+	}
+
+	outputs := map[string]string{
+		"terminating":     fmt.Sprintf("%t", !t.gone),
+		"blockingObjects": t.blockingSummary(),
+	}
+	if force {
+		outputs["finalizersRemoved"] = fmt.Sprintf("%d", len(t.finalized))
+	}
+
+	if force && !t.gone {
+		msg := fmt.Sprintf("Namespace/%s is still terminating", name)
+		if len(t.finalized) > 0 {
+			msg = fmt.Sprintf("Namespace/%s is still present after removing finalizers", name)
+		}
+		errMsg := fmt.Sprintf("namespace %s did not terminate", name)
+		if len(t.conditions) > 0 {
+			errMsg += ": " + strings.Join(t.conditions, "; ")
+		}
+		return &sdk.OperationResult{
+			Success: false,
+			Message: msg,
+			Error:   errMsg,
+			Outputs: outputs,
+		}
+	}
+
+	if t.stuck() {
+		e.LogWarn(ctx, "Namespace is stuck terminating", map[string]any{
+			"name":       name,
+			"conditions": t.conditions,
+			"blocking":   t.blockingSummary(),
+		})
+		msg := fmt.Sprintf("Deleted Namespace/%s, but it is stuck terminating (%s)", name, strings.Join(t.conditions, "; "))
+		if len(t.blocking) > 0 {
+			msg += fmt.Sprintf("; blocked by: %s", t.blockingSummary())
+		}
+		return sdk.SuccessWithOutputs(msg, outputs)
+	}
+
+	if len(t.finalized) > 0 {
+		return sdk.SuccessWithOutputs(fmt.Sprintf("Deleted Namespace/%s after removing finalizers from %d object(s)", name, len(t.finalized)), outputs)
+	}
+	return sdk.SuccessWithOutputs(fmt.Sprintf("Deleted Namespace/%s", name), outputs)
+}
+
+// deleteBySelector deletes all objects of a type matching a label and/or field
+// selector. It uses DeleteCollection and falls back to deleting the listed
+// objects one by one when the collection delete is not allowed.
+func (e *Extension) deleteBySelector(ctx context.Context, actor actor, args map[string]any, deleteOpts metav1.DeleteOptions, listOpts metav1.ListOptions) *sdk.OperationResult {
+	ref, err := parseResourceType(args)
+	if err != nil {
+		return sdk.Failure(err)
+	}
+	if ref.name != "" {
+		return sdk.Failure(fmt.Errorf("metadata.name cannot be combined with labelSelector or fieldSelector"))
+	}
+	if force, _ := args["force"].(bool); force {
+		return sdk.Failure(fmt.Errorf("force cannot be combined with labelSelector or fieldSelector"))
+	}
+
+	gvr, err := ref.gvr()
+	if err != nil {
+		return sdk.Failure(err)
+	}
+
+	e.LogInfo(ctx, "Deleting resources by selector", map[string]any{
+		"kind":          ref.kind,
+		"namespace":     ref.namespace,
+		"labelSelector": listOpts.LabelSelector,
+		"fieldSelector": listOpts.FieldSelector,
+	})
+
+	action := fmt.Sprintf("delete %s objects", ref.kind)
+	list, err := actor.client.List(ctx, gvr, ref.namespace, listOpts)
+	if apierrors.IsForbidden(err) {
+		return actor.forbidden(err, action)
+	}
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to list resources: %w", err))
+	}
+
+	outputs := map[string]string{
+		"matched": fmt.Sprintf("%d", len(list.Items)),
+		"deleted": fmt.Sprintf("%d", len(list.Items)),
+		"method":  "deleteCollection",
+	}
+
+	if len(list.Items) == 0 {
+		outputs["method"] = ""
+		return sdk.SuccessWithOutputs(fmt.Sprintf("No %s objects matched", ref.kind), outputs)
+	}
+
+	// DeleteCollection is only served for a single namespace or cluster-scoped
+	// types, and RBAC may grant delete without deletecollection.
+	err = actor.client.DeleteCollection(ctx, gvr, ref.namespace, deleteOpts, listOpts)
+	if err != nil && !apierrors.IsMethodNotSupported(err) && !apierrors.IsForbidden(err) && !apierrors.IsNotFound(err) {
+		return sdk.Failure(fmt.Errorf("failed to delete collection: %w", err))
+	}
+
+	if err != nil {
+		e.LogInfo(ctx, "DeleteCollection not available, deleting objects one by one", map[string]any{
+			"kind":  ref.kind,
+			"error": err.Error(),
+		})
+		outputs["method"] = "listAndDelete"
+
+		deleted := 0
+		var errs []string
+		var forbiddenErr error
+		for _, item := range list.Items {
+			err := actor.client.Delete(ctx, gvr, item.GetName(), item.GetNamespace(), deleteOpts)
+			if err != nil && !apierrors.IsNotFound(err) {
+				if apierrors.IsForbidden(err) {
+					forbiddenErr = err
+				}
+				errs = append(errs, fmt.Sprintf("%s: %s", namespacedName(item.GetNamespace(), item.GetName()), err.Error()))
+				continue
+			}
+			deleted++
+		}
+		outputs["deleted"] = fmt.Sprintf("%d", deleted)
+
+		// Only a request that deleted nothing counts as forbidden; partial
+		// deletes are reported as failures below.
+		if forbiddenErr != nil && deleted == 0 {
+			return actor.forbidden(forbiddenErr, action)
+		}
+
+		if len(errs) > 0 {
+			return &sdk.OperationResult{
+				Success: false,
+				Message: fmt.Sprintf("Deleted %d of %d %s object(s)", deleted, len(list.Items), ref.kind),
+				Error:   fmt.Sprintf("failed to delete resources: %s", strings.Join(errs, "; ")),
+				Outputs: outputs,
+			}
+		}
+	}
+
+	if err == nil && len(deleteOpts.DryRun) == 0 {
+		// DeleteCollection does not report what it deleted, so the matched
+		// objects are looked up again.
+		remaining, err := remainingObjects(ctx, actor.client, gvr, ref.namespace, listOpts, list.Items)
+		if err != nil {
+			return sdk.Failure(fmt.Errorf("failed to list resources after delete: %w", err))
+		}
+		deleted := len(list.Items) - len(remaining)
+		outputs["deleted"] = fmt.Sprintf("%d", deleted)
+		if len(remaining) > 0 {
+			return &sdk.OperationResult{
+				Success: false,
+				Message: fmt.Sprintf("Deleted %d of %d %s object(s)", deleted, len(list.Items), ref.kind),
+				Error:   fmt.Sprintf("objects still present after delete: %s", strings.Join(remaining, ", ")),
+				Outputs: outputs,
+			}
+		}
+	}
+
+	if r := actor.forbidden(nil, action); r != nil {
+		return r
+	}
+
+	e.LogInfo(ctx, "Resources deleted by selector", map[string]any{
+		"kind":    ref.kind,
+		"deleted": outputs["deleted"],
+		"method":  outputs["method"],
+	})
+
+	msg := fmt.Sprintf("Deleted %s %s object(s)", outputs["deleted"], ref.kind)
+	if len(deleteOpts.DryRun) > 0 {
+		msg += " (dry run)"
+	}
+	return sdk.SuccessWithOutputs(msg, outputs)
+}
+
+// remainingObjects lists the objects matching listOpts again and returns the
+// names of those in matched that are still present and not being deleted.
+func remainingObjects(ctx context.Context, client ResourceClient, gvr schema.GroupVersionResource, namespace string, listOpts metav1.ListOptions, matched []unstructured.Unstructured) ([]string, error) {
+	list, err := client.List(ctx, gvr, namespace, listOpts)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[types.UID]bool, len(list.Items))
+	for _, item := range list.Items {
+		if item.GetDeletionTimestamp() == nil {
+			present[item.GetUID()] = true
+		}
+	}
+	var remaining []string
+	for _, item := range matched {
+		if present[item.GetUID()] {
+			remaining = append(remaining, namespacedName(item.GetNamespace(), item.GetName()))
+		}
+	}
+	return remaining, nil
+}
+
+// propagationPolicies are the accepted values of the propagationPolicy argument.
+var propagationPolicies = []metav1.DeletionPropagation{
+	metav1.DeletePropagationForeground,
+	metav1.DeletePropagationBackground,
+	metav1.DeletePropagationOrphan,
+}
+
+// parseDeleteOptions builds the delete options from the propagationPolicy,
+// gracePeriodSeconds, preconditions and dryRun arguments. Propagation defaults
+// to Foreground.
+func parseDeleteOptions(args map[string]any) (metav1.DeleteOptions, error) {
+	propagation := metav1.DeletePropagationForeground
+	if policy, _ := args["propagationPolicy"].(string); policy != "" {
+		propagation = metav1.DeletionPropagation(policy)
+		if !slices.Contains(propagationPolicies, propagation) {
+			return metav1.DeleteOptions{}, fmt.Errorf("propagationPolicy must be one of Foreground, Background, Orphan, got %q", policy)
+		}
+	}
+	opts := metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	}
+
+	gracePeriod, ok, err := intArg(args, "gracePeriodSeconds")
+	if err != nil {
+		return metav1.DeleteOptions{}, err
+	}
+	if ok {
+		if gracePeriod < 0 {
+			return metav1.DeleteOptions{}, fmt.Errorf("gracePeriodSeconds must not be negative")
+		}
+		seconds := int64(gracePeriod)
+		opts.GracePeriodSeconds = &seconds
+	}
+
+	if raw, ok := args["preconditions"]; ok && raw != nil {
+		preconditions, ok := raw.(map[string]any)
+		if !ok {
+			return metav1.DeleteOptions{}, fmt.Errorf("preconditions must be an object")
+		}
+		uid, _ := preconditions["uid"].(string)
+		resourceVersion, _ := preconditions["resourceVersion"].(string)
+		if uid == "" && resourceVersion == "" {
+			return metav1.DeleteOptions{}, fmt.Errorf("preconditions must set uid or resourceVersion")
+		}
+		opts.Preconditions = &metav1.Preconditions{}
+		if uid != "" {
+			u := types.UID(uid)
+			opts.Preconditions.UID = &u
+		}
+		if resourceVersion != "" {
+			opts.Preconditions.ResourceVersion = &resourceVersion
+		}
+	}
+
+	if dryRun, _ := args["dryRun"].(bool); dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	return opts, nil
+}
@@ -2,12 +2,17 @@ package extension
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestHandleDelete(t *testing.T) {
@@ -16,6 +21,7 @@ func TestHandleDelete(t *testing.T) {
 		args        any
 		client      *mockClient
 		wantSuccess bool
+		wantOutputs map[string]string
 	}{
 		{
 			name: "successful delete",
@@ -59,6 +65,128 @@ func TestHandleDelete(t *testing.T) {
 			},
 			wantSuccess: false,
 		},
+		{
+			name: "force fails when finalizers cannot be removed",
+			args: map[string]any{
+				"apiVersion":         "example.com/v1",
+				"kind":               "Widget",
+				"metadata":           map[string]any{"name": "gadget", "namespace": "default"},
+				"force":              true,
+				"terminationTimeout": "0s",
+			},
+			client: &mockClient{
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					obj := &unstructured.Unstructured{Object: map[string]any{}}
+					obj.SetFinalizers([]string{"example.com/operator"})
+					return obj, nil
+				},
+				patchFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+					return nil, apierrors.NewForbidden(gvr.GroupResource(), name, nil)
+				},
+			},
+			wantSuccess: false,
+		},
+		{
+			name: "stuck namespace is reported",
+			args: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]any{"name": "test-abc"},
+			},
+			client: &mockClient{
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					return terminatingNamespace(name, namespaceContentRemaining), nil
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{"terminating": "true"},
+		},
+		{
+			name: "delete by label selector uses DeleteCollection",
+			args: map[string]any{
+				"apiVersion":    "v1",
+				"kind":          "Pod",
+				"metadata":      map[string]any{"namespace": "default"},
+				"labelSelector": "app=web",
+			},
+			client: collectionDeleteClient(func(items []unstructured.Unstructured) []unstructured.Unstructured {
+				return nil
+			}),
+			wantSuccess: true,
+			wantOutputs: map[string]string{"matched": "3", "deleted": "3", "method": "deleteCollection"},
+		},
+		{
+			name: "DeleteCollection reports objects that are still present",
+			args: map[string]any{
+				"apiVersion":    "v1",
+				"kind":          "Pod",
+				"metadata":      map[string]any{"namespace": "default"},
+				"labelSelector": "app=web",
+			},
+			client: collectionDeleteClient(func(items []unstructured.Unstructured) []unstructured.Unstructured {
+				// b is terminating, c was left alone and d was created afterwards.
+				now := metav1.Now()
+				items[1].SetDeletionTimestamp(&now)
+				d := unstructured.Unstructured{Object: map[string]any{}}
+				d.SetName("d")
+				d.SetUID("uid-d")
+				return append(items[1:], d)
+			}),
+			wantSuccess: false,
+			wantOutputs: map[string]string{"matched": "3", "deleted": "2", "method": "deleteCollection"},
+		},
+		{
+			name: "delete by field selector falls back to list and delete",
+			args: map[string]any{
+				"apiVersion":    "v1",
+				"kind":          "Pod",
+				"fieldSelector": "status.phase=Failed",
+			},
+			client: &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					a := unstructured.Unstructured{Object: map[string]any{}}
+					a.SetName("a")
+					a.SetNamespace("ns1")
+					b := unstructured.Unstructured{Object: map[string]any{}}
+					b.SetName("b")
+					b.SetNamespace("ns2")
+					return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{a, b}}, nil
+				},
+				deleteCollectionFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+					return apierrors.NewMethodNotSupported(gvr.GroupResource(), "deletecollection")
+				},
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					if namespace == "" {
+						return errors.New("expected the object's namespace")
+					}
+					return nil
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{"deleted": "2", "method": "listAndDelete"},
+		},
+		{
+			name: "selector with no matches",
+			args: map[string]any{
+				"apiVersion":    "v1",
+				"kind":          "Pod",
+				"labelSelector": "app=none",
+			},
+			client:      &mockClient{},
+			wantSuccess: true,
+			wantOutputs: map[string]string{"deleted": "0"},
+		},
+		{
+			name: "selector cannot be combined with name",
+			args: map[string]any{
+				"apiVersion":    "v1",
+				"kind":          "Pod",
+				"metadata":      map[string]any{"name": "web"},
+				"labelSelector": "app=web",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -77,6 +205,236 @@ func TestHandleDelete(t *testing.T) {
 			if result.Success != tt.wantSuccess {
 				t.Errorf("handleDelete() success = %v, want %v", result.Success, tt.wantSuccess)
 			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("output %s = %q, want %q", k, got, want)
+				}
+			}
 		})
 	}
 }
+
+// collectionDeleteClient serves the pods a, b and c for app=web until
+// DeleteCollection is called, and what after returns from them afterwards.
+func collectionDeleteClient(after func(items []unstructured.Unstructured) []unstructured.Unstructured) *mockClient {
+	deleted := false
+	return &mockClient{
+		listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+			if opts.LabelSelector != "app=web" {
+				return nil, errors.New("unexpected selector " + opts.LabelSelector)
+			}
+			var items []unstructured.Unstructured
+			for _, name := range []string{"a", "b", "c"} {
+				item := unstructured.Unstructured{Object: map[string]any{}}
+				item.SetName(name)
+				item.SetNamespace(namespace)
+				item.SetUID(types.UID("uid-" + name))
+				items = append(items, item)
+			}
+			if deleted {
+				items = after(items)
+			}
+			return &unstructured.UnstructuredList{Items: items}, nil
+		},
+		deleteCollectionFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+			deleted = true
+			return nil
+		},
+		deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+			return errors.New("unexpected single delete")
+		},
+	}
+}
+
+func TestHandleDeleteOptions(t *testing.T) {
+	base := func(extra map[string]any) map[string]any {
+		args := map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]any{"name": "web", "namespace": "default"},
+		}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	tests := []struct {
+		name           string
+		args           map[string]any
+		deleteErr      error
+		wantSuccess    bool
+		wantErrContain string
+		checkOpts      func(t *testing.T, opts metav1.DeleteOptions)
+	}{
+		{
+			name:        "defaults to foreground propagation",
+			args:        base(nil),
+			wantSuccess: true,
+			checkOpts: func(t *testing.T, opts metav1.DeleteOptions) {
+				if *opts.PropagationPolicy != metav1.DeletePropagationForeground {
+					t.Errorf("propagation = %s, want Foreground", *opts.PropagationPolicy)
+				}
+				if opts.GracePeriodSeconds != nil || opts.Preconditions != nil || len(opts.DryRun) > 0 {
+					t.Errorf("unexpected options %+v", opts)
+				}
+			},
+		},
+		{
+			name: "all options",
+			args: base(map[string]any{
+				"propagationPolicy":  "Orphan",
+				"gracePeriodSeconds": float64(0),
+				"preconditions":      map[string]any{"uid": "abc", "resourceVersion": "42"},
+				"dryRun":             true,
+			}),
+			wantSuccess: true,
+			checkOpts: func(t *testing.T, opts metav1.DeleteOptions) {
+				if *opts.PropagationPolicy != metav1.DeletePropagationOrphan {
+					t.Errorf("propagation = %s, want Orphan", *opts.PropagationPolicy)
+				}
+				if opts.GracePeriodSeconds == nil || *opts.GracePeriodSeconds != 0 {
+					t.Errorf("gracePeriodSeconds = %v, want 0", opts.GracePeriodSeconds)
+				}
+				if opts.Preconditions == nil || *opts.Preconditions.UID != "abc" || *opts.Preconditions.ResourceVersion != "42" {
+					t.Errorf("unexpected preconditions %+v", opts.Preconditions)
+				}
+				if len(opts.DryRun) != 1 || opts.DryRun[0] != metav1.DryRunAll {
+					t.Errorf("dryRun = %v, want [All]", opts.DryRun)
+				}
+			},
+		},
+		{
+			name:        "force defaults to background propagation",
+			args:        base(map[string]any{"force": true}),
+			wantSuccess: true,
+			checkOpts: func(t *testing.T, opts metav1.DeleteOptions) {
+				if *opts.PropagationPolicy != metav1.DeletePropagationBackground {
+					t.Errorf("propagation = %s, want Background", *opts.PropagationPolicy)
+				}
+			},
+		},
+		{
+			name:        "force keeps an explicit propagation policy",
+			args:        base(map[string]any{"force": true, "propagationPolicy": "Foreground"}),
+			wantSuccess: true,
+			checkOpts: func(t *testing.T, opts metav1.DeleteOptions) {
+				if *opts.PropagationPolicy != metav1.DeletePropagationForeground {
+					t.Errorf("propagation = %s, want Foreground", *opts.PropagationPolicy)
+				}
+			},
+		},
+		{
+			name:           "schema rejects unknown propagation policy",
+			args:           base(map[string]any{"propagationPolicy": "Cascade"}),
+			wantErrContain: "invalid arguments",
+		},
+		{
+			name:           "schema rejects negative grace period",
+			args:           base(map[string]any{"gracePeriodSeconds": float64(-1)}),
+			wantErrContain: "invalid arguments",
+		},
+		{
+			name:           "schema rejects unknown precondition",
+			args:           base(map[string]any{"preconditions": map[string]any{"generation": "1"}}),
+			wantErrContain: "invalid arguments",
+		},
+		{
+			name:           "empty preconditions",
+			args:           base(map[string]any{"preconditions": map[string]any{}}),
+			wantErrContain: "preconditions must set uid or resourceVersion",
+		},
+		{
+			name:           "failed precondition",
+			args:           base(map[string]any{"preconditions": map[string]any{"uid": "abc"}}),
+			deleteErr:      apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "web", errors.New("UID mismatch")),
+			wantErrContain: "precondition failed for Pod/web",
+		},
+		{
+			name: "preconditions cannot be combined with selectors",
+			args: map[string]any{
+				"apiVersion":    "v1",
+				"kind":          "Pod",
+				"labelSelector": "app=web",
+				"preconditions": map[string]any{"uid": "abc"},
+			},
+			wantErrContain: "preconditions cannot be combined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOpts *metav1.DeleteOptions
+			client := &mockClient{
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					gotOpts = &opts
+					return tt.deleteErr
+				},
+			}
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+			}
+
+			handler := withSchema(deleteParams, ext.handleDelete)
+			result, err := handler(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if tt.checkOpts != nil {
+				if gotOpts == nil {
+					t.Fatal("expected delete to be called")
+				}
+				tt.checkOpts(t, *gotOpts)
+			}
+		})
+	}
+}
+
+func TestForceDeleteKeepsGarbageCollectorFinalizers(t *testing.T) {
+	var patches []string
+	client := &mockClient{
+		getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+			obj := &unstructured.Unstructured{Object: map[string]any{}}
+			obj.SetName(name)
+			obj.SetResourceVersion("7")
+			obj.SetFinalizers([]string{metav1.FinalizerDeleteDependents, "example.com/operator"})
+			return obj, nil
+		},
+		patchFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+			patches = append(patches, string(data))
+			return &unstructured.Unstructured{Object: map[string]any{}}, nil
+		},
+	}
+	ext := &Extension{
+		Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:    client,
+	}
+
+	args := map[string]any{
+		"apiVersion":         "example.com/v1",
+		"kind":               "Widget",
+		"metadata":           map[string]any{"name": "gadget", "namespace": "default"},
+		"force":              true,
+		"propagationPolicy":  "Foreground",
+		"terminationTimeout": "1s",
+	}
+	start := time.Now()
+	result, err := ext.handleDelete(context.Background(), &sdk.OperationRequest{Args: args})
+	if err != nil || !result.Success {
+		t.Fatalf("delete failed: %v %s", err, result.Error)
+	}
+	if time.Since(start) < time.Second {
+		t.Error("expected finalizers to be kept until terminationTimeout expired")
+	}
+	want := `{"metadata":{"finalizers":["foregroundDeletion"],"resourceVersion":"7"}}`
+	if len(patches) != 1 || patches[0] != want {
+		t.Errorf("patches = %v, want %s", patches, want)
+	}
+}
@@ -0,0 +1,301 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// maxDescribeDepth bounds how far owned objects are followed (CronJob → Job → Pod).
+	maxDescribeDepth = 3
+	// maxDescribeEvents is the number of most recent events reported per object.
+	maxDescribeEvents = 10
+)
+
+// handleDescribe builds a kubectl describe-style report of an object, the objects
+// it owns and their related events.
+func (e *Extension) handleDescribe(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	ref, err := parseResourceRef(args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	gvr, err := ref.gvr()
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	e.LogInfo(ctx, "Describing resource", map[string]any{
+		"kind":      ref.kind,
+		"name":      ref.name,
+		"namespace": ref.namespace,
+	})
+
+	obj, err := e.kube(ctx).Get(ctx, gvr, ref.name, ref.namespace)
+	if err != nil {
+		e.LogError(ctx, "Failed to get resource", map[string]any{
+			"kind":  ref.kind,
+			"name":  ref.name,
+			"error": err.Error(),
+		})
+		return sdk.Failure(fmt.Errorf("failed to get %s/%s: %w", ref.kind, ref.name, err)), nil
+	}
+
+	var report strings.Builder
+	owned, err := e.listOwnedObjects(ctx, obj)
+	if err != nil {
+		fmt.Fprintf(&report, "Failed to list owned objects: %v\n", err)
+	}
+	count := e.describeObject(ctx, &report, obj, owned, 0)
+
+	e.LogInfo(ctx, "Describe report", map[string]any{
+		"kind":   ref.kind,
+		"name":   ref.name,
+		"report": report.String(),
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Described %s/%s and %d related object(s)\n%s", ref.kind, ref.name, count-1, report.String()),
+		map[string]string{
+			"report":  report.String(),
+			"objects": fmt.Sprintf("%d", count),
+		},
+	), nil
+}
+
+// listOwnedObjects lists the objects in the scope of obj, its namespace or the
+// cluster, and indexes them by the UID of their controller. Resource types that
+// cannot be listed, e.g. because their aggregated API is unavailable, are skipped.
+func (e *Extension) listOwnedObjects(ctx context.Context, obj *unstructured.Unstructured) (map[types.UID][]*unstructured.Unstructured, error) {
+	resources, err := e.discoverResources(ctx, "list")
+	if err != nil {
+		return nil, err
+	}
+
+	namespaced := obj.GetNamespace() != ""
+	owned := make(map[types.UID][]*unstructured.Unstructured)
+	for _, r := range resources {
+		if r.namespaced != namespaced {
+			continue
+		}
+		list, err := e.kube(ctx).List(ctx, r.gvr, obj.GetNamespace(), metav1.ListOptions{})
+		if err != nil {
+			continue
+		}
+		for i := range list.Items {
+			if controller := metav1.GetControllerOf(&list.Items[i]); controller != nil {
+				owned[controller.UID] = append(owned[controller.UID], &list.Items[i])
+			}
+		}
+	}
+
+	for _, children := range owned {
+		sort.Slice(children, func(i, j int) bool {
+			if children[i].GetKind() != children[j].GetKind() {
+				return children[i].GetKind() < children[j].GetKind()
+			}
+			return children[i].GetName() < children[j].GetName()
+		})
+	}
+
+	return owned, nil
+}
+
+// describeObject writes the report section for obj and, recursively, the objects
+// it controls. It returns the number of objects described.
+func (e *Extension) describeObject(ctx context.Context, w *strings.Builder, obj *unstructured.Unstructured, owned map[types.UID][]*unstructured.Unstructured, depth int) int {
+	indent := strings.Repeat("  ", depth)
+
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+	fmt.Fprintf(w, "%s%s %s\n", indent, obj.GetKind(), name)
+
+	if obj.GetKind() == "Pod" {
+		describePodStatus(w, obj, indent+"  ")
+	} else {
+		describeReplicas(w, obj, indent+"  ")
+	}
+	describeConditions(w, obj, indent+"  ")
+	e.describeEvents(ctx, w, obj, indent+"  ")
+
+	count := 1
+	if depth >= maxDescribeDepth {
+		return count
+	}
+
+	for _, child := range owned[obj.GetUID()] {
+		count += e.describeObject(ctx, w, child, owned, depth+1)
+	}
+
+	return count
+}
+
+// describeReplicas writes the replica counts of workload controllers.
+func describeReplicas(w *strings.Builder, obj *unstructured.Unstructured, indent string) {
+	desired, hasDesired, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !hasDesired {
+		return
+	}
+	current, _, _ := unstructured.NestedInt64(obj.Object, "status", "replicas")
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	fmt.Fprintf(w, "%sReplicas: %d desired, %d current, %d ready, %d available\n", indent, desired, current, ready, available)
+}
+
+// describePodStatus writes the phase and container states of a pod.
+func describePodStatus(w *strings.Builder, pod *unstructured.Unstructured, indent string) {
+	phase, _, _ := unstructured.NestedString(pod.Object, "status", "phase")
+	fmt.Fprintf(w, "%sPhase: %s\n", indent, phase)
+	if node, _, _ := unstructured.NestedString(pod.Object, "spec", "nodeName"); node != "" {
+		fmt.Fprintf(w, "%sNode: %s\n", indent, node)
+	}
+
+	for _, field := range []string{"initContainerStatuses", "containerStatuses"} {
+		statuses, _, _ := unstructured.NestedSlice(pod.Object, "status", field)
+		if len(statuses) == 0 {
+			continue
+		}
+		if field == "initContainerStatuses" {
+			fmt.Fprintf(w, "%sInit Containers:\n", indent)
+		} else {
+			fmt.Fprintf(w, "%sContainers:\n", indent)
+		}
+		for _, s := range statuses {
+			status, ok := s.(map[string]any)
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(w, "%s  %s\n", indent, describeContainerStatus(status))
+		}
+	}
+}
+
+// describeContainerStatus formats a single container status as one line.
+func describeContainerStatus(status map[string]any) string {
+	name, _, _ := unstructured.NestedString(status, "name")
+	ready, _, _ := unstructured.NestedBool(status, "ready")
+	restarts, _, _ := unstructured.NestedInt64(status, "restartCount")
+
+	state := "unknown"
+	if running, ok, _ := unstructured.NestedMap(status, "state", "running"); ok && running != nil {
+		state = "running"
+	} else if waiting, ok, _ := unstructured.NestedMap(status, "state", "waiting"); ok {
+		state = describeState("waiting", waiting)
+	} else if terminated, ok, _ := unstructured.NestedMap(status, "state", "terminated"); ok {
+		state = describeState("terminated", terminated)
+	}
+
+	line := fmt.Sprintf("%s: %s, ready=%v, restarts=%d", name, state, ready, restarts)
+	if restarts > 0 {
+		if last, ok, _ := unstructured.NestedMap(status, "lastState", "terminated"); ok {
+			line += fmt.Sprintf(", last %s", describeState("terminated", last))
+		}
+	}
+	return line
+}
+
+// describeState formats a waiting or terminated container state.
+func describeState(state string, fields map[string]any) string {
+	reason, _ := fields["reason"].(string)
+	message, _ := fields["message"].(string)
+	if exitCode, ok := fields["exitCode"]; ok {
+		state += fmt.Sprintf(" (exit code %v)", exitCode)
+	}
+	if reason != "" {
+		state += " " + reason
+	}
+	if message != "" {
+		state += ": " + message
+	}
+	return state
+}
+
+// describeConditions writes the status conditions of an object.
+func describeConditions(w *strings.Builder, obj *unstructured.Unstructured, indent string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if len(conditions) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%sConditions:\n", indent)
+	for _, c := range conditions {
+		cond, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		condType, _ := cond["type"].(string)
+		condStatus, _ := cond["status"].(string)
+		line := fmt.Sprintf("%s  %s=%s", indent, condType, condStatus)
+		if reason, _ := cond["reason"].(string); reason != "" {
+			line += fmt.Sprintf(" (%s)", reason)
+		}
+		if message, _ := cond["message"].(string); message != "" {
+			line += ": " + message
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// describeEvents writes the most recent events involving obj.
+func (e *Extension) describeEvents(ctx context.Context, w *strings.Builder, obj *unstructured.Unstructured, indent string) {
+	events, err := e.kube(ctx).List(ctx, eventGVR, obj.GetNamespace(), metav1.ListOptions{
+		FieldSelector: "involvedObject.uid=" + string(obj.GetUID()),
+	})
+	if err != nil {
+		fmt.Fprintf(w, "%sFailed to list events: %v\n", indent, err)
+		return
+	}
+	if len(events.Items) == 0 {
+		return
+	}
+
+	items := events.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventTime(&items[i]) < eventTime(&items[j])
+	})
+	if len(items) > maxDescribeEvents {
+		items = items[len(items)-maxDescribeEvents:]
+	}
+
+	fmt.Fprintf(w, "%sEvents:\n", indent)
+	for _, ev := range items {
+		eventType, _, _ := unstructured.NestedString(ev.Object, "type")
+		reason, _, _ := unstructured.NestedString(ev.Object, "reason")
+		message, _, _ := unstructured.NestedString(ev.Object, "message")
+		count, _, _ := unstructured.NestedInt64(ev.Object, "count")
+		line := fmt.Sprintf("%s  %s %s", indent, eventType, reason)
+		if count > 1 {
+			line += fmt.Sprintf(" (x%d)", count)
+		}
+		fmt.Fprintf(w, "%s: %s\n", line, strings.TrimSpace(message))
+	}
+}
+
+// eventTime returns the most relevant timestamp of an event in RFC 3339 format,
+// which sorts lexically.
+func eventTime(ev *unstructured.Unstructured) string {
+	for _, field := range []string{"lastTimestamp", "eventTime", "firstTimestamp"} {
+		if ts, _, _ := unstructured.NestedString(ev.Object, field); ts != "" {
+			return ts
+		}
+	}
+	return ev.GetCreationTimestamp().UTC().Format("2006-01-02T15:04:05Z")
+}
@@ -0,0 +1,248 @@
+package extension
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func ownedObject(kind, name, uid, ownerUID string) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]any{
+		"kind": kind,
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": "default",
+			"uid":       uid,
+			"ownerReferences": []any{
+				map[string]any{"kind": "Owner", "name": "owner", "uid": ownerUID, "controller": true},
+			},
+		},
+	}}
+}
+
+func TestHandleDescribe(t *testing.T) {
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	replicaSetGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}
+	statefulSetGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
+	databaseGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "databases"}
+
+	deployment := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "web", "namespace": "default", "uid": "deploy-uid"},
+		"spec":       map[string]any{"replicas": int64(1)},
+		"status": map[string]any{
+			"conditions": []any{
+				map[string]any{"type": "Available", "status": "False", "reason": "MinimumReplicasUnavailable"},
+			},
+		},
+	}}
+
+	pod := ownedObject("Pod", "web-abc-xyz", "pod-uid", "rs-uid")
+	pod.Object["status"] = map[string]any{
+		"phase": "Pending",
+		"containerStatuses": []any{
+			map[string]any{
+				"name":         "app",
+				"ready":        false,
+				"restartCount": int64(0),
+				"state": map[string]any{
+					"waiting": map[string]any{"reason": "ImagePullBackOff", "message": "Back-off pulling image"},
+				},
+			},
+		},
+	}
+
+	database := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Database",
+		"metadata":   map[string]any{"name": "db", "namespace": "default", "uid": "db-uid"},
+	}}
+
+	// A ConfigMap that refers to the deployment without being controlled by it.
+	referencing := ownedObject("ConfigMap", "web-notes", "cm-uid", "deploy-uid")
+	referencing.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "deploy-uid"}})
+
+	client := &mockClient{
+		getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+			switch name {
+			case "web":
+				return deployment, nil
+			case "db":
+				return database, nil
+			}
+			return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+		},
+		discoverResourcesFn: func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+			return []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"list"}},
+						{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list"}},
+						{Name: "nodes", Kind: "Node", Namespaced: false, Verbs: []string{"list"}},
+					},
+				},
+				{
+					GroupVersion: "apps/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "replicasets", Kind: "ReplicaSet", Namespaced: true, Verbs: []string{"list"}},
+						{Name: "statefulsets", Kind: "StatefulSet", Namespaced: true, Verbs: []string{"list"}},
+					},
+				},
+				{
+					GroupVersion: "example.com/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "databases", Kind: "Database", Namespaced: true, Verbs: []string{"list"}},
+					},
+				},
+			}, nil
+		},
+		listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+			switch gvr {
+			case replicaSetGVR:
+				return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+					ownedObject("ReplicaSet", "web-abc", "rs-uid", "deploy-uid"),
+					ownedObject("ReplicaSet", "other", "other-uid", "other-deploy"),
+				}}, nil
+			case statefulSetGVR:
+				return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+					ownedObject("StatefulSet", "db-primary", "sts-uid", "db-uid"),
+				}}, nil
+			case configMapGVR:
+				return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{referencing}}, nil
+			case databaseGVR:
+				return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*database}}, nil
+			case podGVR:
+				if namespace == "" {
+					t.Errorf("pods listed across all namespaces")
+				}
+				return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+					pod,
+					ownedObject("Pod", "db-primary-0", "db-pod-uid", "sts-uid"),
+				}}, nil
+			case eventGVR:
+				if opts.FieldSelector != "involvedObject.uid=pod-uid" {
+					return &unstructured.UnstructuredList{}, nil
+				}
+				return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+					{Object: map[string]any{
+						"type":    "Warning",
+						"reason":  "Failed",
+						"message": "Failed to pull image \"nginx:nope\"",
+						"count":   int64(3),
+					}},
+				}}, nil
+			}
+			return &unstructured.UnstructuredList{}, nil
+		},
+	}
+
+	tests := []struct {
+		name         string
+		args         any
+		wantSuccess  bool
+		wantObjects  string
+		wantContains []string
+		wantExcludes []string
+	}{
+		{
+			name: "deployment with owned objects",
+			args: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "web", "namespace": "default"},
+			},
+			wantSuccess: true,
+			wantObjects: "3",
+			wantContains: []string{
+				"Deployment default/web",
+				"Replicas: 1 desired",
+				"Available=False (MinimumReplicasUnavailable)",
+				"  ReplicaSet default/web-abc",
+				"    Pod default/web-abc-xyz",
+				"Phase: Pending",
+				"app: waiting ImagePullBackOff: Back-off pulling image",
+				"Warning Failed (x3): Failed to pull image",
+			},
+			wantExcludes: []string{"other", "web-notes", "db-primary"},
+		},
+		{
+			name: "custom resource with owned objects",
+			args: map[string]any{
+				"apiVersion": "example.com/v1",
+				"kind":       "Database",
+				"metadata":   map[string]any{"name": "db", "namespace": "default"},
+			},
+			wantSuccess: true,
+			wantObjects: "3",
+			wantContains: []string{
+				"Database default/db",
+				"  StatefulSet default/db-primary",
+				"    Pod default/db-primary-0",
+			},
+			wantExcludes: []string{"web"},
+		},
+		{
+			name: "resource not found",
+			args: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "missing", "namespace": "default"},
+			},
+			wantSuccess: false,
+		},
+		{
+			name: "missing name",
+			args: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{},
+			},
+			wantSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+			}
+
+			req := &sdk.OperationRequest{Args: tt.args}
+			result, err := ext.handleDescribe(context.Background(), req)
+
+			if err != nil {
+				t.Fatalf("handleDescribe() returned error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("handleDescribe() success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if !tt.wantSuccess {
+				return
+			}
+
+			report := result.Outputs["report"]
+			if got := result.Outputs["objects"]; got != tt.wantObjects {
+				t.Errorf("handleDescribe() objects = %s, want %s\n%s", got, tt.wantObjects, report)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(report, want) {
+					t.Errorf("report does not contain %q:\n%s", want, report)
+				}
+			}
+			for _, unwanted := range tt.wantExcludes {
+				if strings.Contains(report, unwanted) {
+					t.Errorf("report unexpectedly contains %q:\n%s", unwanted, report)
+				}
+			}
+		})
+	}
+}
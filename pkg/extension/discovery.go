@@ -0,0 +1,58 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// apiResource describes a resource type served by the cluster.
+type apiResource struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+	verbs      []string
+}
+
+// discoverResources returns all top-level resource types (no subresources)
+// that support every one of the given verbs.
+func (e *Extension) discoverResources(ctx context.Context, verbs ...string) ([]apiResource, error) {
+	lists, err := e.kube(ctx).DiscoverResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API resources: %w", err)
+	}
+
+	var resources []apiResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			supported := true
+			for _, verb := range verbs {
+				if !slices.Contains(r.Verbs, verb) {
+					supported = false
+					break
+				}
+			}
+			if !supported {
+				continue
+			}
+			resources = append(resources, apiResource{
+				gvr:        gv.WithResource(r.Name),
+				kind:       r.Kind,
+				namespaced: r.Namespaced,
+				verbs:      r.Verbs,
+			})
+		}
+	}
+
+	return resources, nil
+}
@@ -3,25 +3,72 @@ package extension
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
-	"k8s.io/client-go/dynamic"
-	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 // Extension wraps the SDK extension with Kubernetes client
 type Extension struct {
 	*sdk.Extension
-	client         ResourceClient
+	client ResourceClient
+	// kubeconfigPath is the kubeconfig setting. When it is empty, the files
+	// listed in KUBECONFIG, or ~/.kube/config, are merged instead.
 	kubeconfigPath string
+	// loadingRules locate the kubeconfig files, or are nil when the extension
+	// uses the in-cluster service account configuration.
+	loadingRules *clientcmd.ClientConfigLoadingRules
+
+	// kubeContext is the kubeconfig context of client; an empty name means the
+	// current context. Operations select other contexts with the context
+	// argument, whose clients are created by newContextClient and cached.
+	kubeContext      string
+	newContextClient func(name string) (ResourceClient, error)
+	contextMu        sync.Mutex
+	contextClients   map[string]ResourceClient
+
+	// kubeconfigBackups hold the original kubeconfig files, recorded before
+	// the first modification, that restoreKubeconfig writes back.
+	kubeconfigMu      sync.Mutex
+	kubeconfigBackups []kubeconfigBackup
+
+	// runID and taskLabel are applied as labels to every object the extension creates.
+	runID     string
+	taskLabel string
+
+	// cleanupOnShutdown makes Run delete tracked resources and generated
+	// namespaces, bounded by shutdownTimeout, when the extension stops.
+	cleanupOnShutdown bool
+	shutdownTimeout   time.Duration
+
+	// stateFile optionally persists the tracked namespaces and resources so that
+	// gcStale can collect what a crashed run left behind once namespaceTTL expires.
+	stateFile    string
+	stateMu      sync.Mutex
+	namespaceTTL time.Duration
+
+	// namespaceProfiles are the named setups createNamespace can apply.
+	namespaceProfiles map[string]namespaceProfile
 
 	mu                  sync.Mutex
 	generatedNamespaces []string
+	// namespaceContexts maps generated namespaces created outside the
+	// default kubeconfig context to their context.
+	namespaceContexts map[string]string
+	trackedResources  []trackedResource
+	// tempFiles hold credentials written by the extension, such as generated
+	// kubeconfigs, and are removed at cleanup or when the extension stops.
+	tempFiles []tempFile
+	// sandboxKubeconfigs are the kubeconfig copies handed to MCP servers,
+	// with their initial content for diffSandboxKubeconfig.
+	sandboxKubeconfigs []sandboxKubeconfig
 }
 
 // New creates a new Kubernetes extension
@@ -48,53 +95,142 @@ func (e *Extension) handleInitialize(config map[string]any) error {
 	}
 
 	// Expand ~ to home directory
-	if strings.HasPrefix(kubeconfigPath, "~") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
-		}
-		kubeconfigPath = filepath.Join(home, kubeconfigPath[1:])
+	kubeconfigPath, err := expandHome(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	loadingRules, err := kubeconfigLoadingRules(kubeconfigPath)
+	if err != nil {
+		return err
 	}
 
-	// If no kubeconfig specified, use default
-	if kubeconfigPath == "" {
-		home, err := os.UserHomeDir()
+	kubeContext, _ := config["context"].(string)
+	client, err := newContextClientAdapter(loadingRules, kubeContext)
+	if err != nil {
+		return err
+	}
+
+	runID, _ := config["runId"].(string)
+	if runID == "" {
+		runID, err = generateID(8)
 		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
+			return err
 		}
-		kubeconfigPath = filepath.Join(home, ".kube", "config")
+	}
+	if errs := validation.IsValidLabelValue(runID); len(errs) > 0 {
+		return fmt.Errorf("invalid runId %q: %s", runID, strings.Join(errs, ", "))
 	}
 
-	// Validate kubeconfig file exists
-	if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
-		return fmt.Errorf("kubeconfig not found: %s", kubeconfigPath)
+	taskLabel, _ := config["taskLabel"].(string)
+	if errs := validation.IsValidLabelValue(taskLabel); len(errs) > 0 {
+		return fmt.Errorf("invalid taskLabel %q: %s", taskLabel, strings.Join(errs, ", "))
 	}
 
-	kubeconfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
-	if err != nil {
-		return fmt.Errorf("failed to build kubeconfig from %s: %w", kubeconfigPath, err)
+	cleanupOnShutdown, _ := config["cleanupOnShutdown"].(bool)
+
+	shutdownTimeout := defaultShutdownTimeout
+	if timeoutStr, _ := config["shutdownTimeout"].(string); timeoutStr != "" {
+		shutdownTimeout, err = time.ParseDuration(timeoutStr)
+		if err != nil {
+			return fmt.Errorf("invalid shutdownTimeout: %w", err)
+		}
 	}
 
-	client, err := dynamic.NewForConfig(kubeconfig)
+	stateFile, _ := config["stateFile"].(string)
+	stateFile, err = expandHome(stateFile)
 	if err != nil {
-		return fmt.Errorf("failed to create dynamic client: %w", err)
+		return err
 	}
 
-	authzClient, err := authorizationv1client.NewForConfig(kubeconfig)
-	if err != nil {
-		return fmt.Errorf("failed to create authorization client: %w", err)
+	namespaceTTL := defaultNamespaceTTL
+	if ttlStr, _ := config["namespaceTTL"].(string); ttlStr != "" {
+		namespaceTTL, err = time.ParseDuration(ttlStr)
+		if err != nil {
+			return fmt.Errorf("invalid namespaceTTL: %w", err)
+		}
 	}
 
-	e.client = &dynamicClientAdapter{
-		client:         client,
-		authzClient:    authzClient,
-		kubeconfigPath: kubeconfigPath,
+	gcOnStartup, _ := config["gcOnStartup"].(bool)
+
+	namespaceProfiles, err := parseNamespaceProfiles(config["namespaceProfiles"])
+	if err != nil {
+		return err
 	}
+
+	e.client = client
 	e.kubeconfigPath = kubeconfigPath
+	e.loadingRules = loadingRules
+	e.kubeContext = kubeContext
+	e.newContextClient = func(name string) (ResourceClient, error) {
+		return newContextClientAdapter(loadingRules, name)
+	}
+	e.runID = runID
+	e.taskLabel = taskLabel
+	e.cleanupOnShutdown = cleanupOnShutdown
+	e.shutdownTimeout = shutdownTimeout
+	e.stateFile = stateFile
+	e.namespaceTTL = namespaceTTL
+	e.namespaceProfiles = namespaceProfiles
+
+	if gcOnStartup {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultGCTimeout)
+		defer cancel()
+		result := e.gcStale(ctx)
+		log.Printf("startup gc: collected %d stale namespace(s) and %d resource(s)", len(result.namespaces), result.resources)
+		for _, err := range result.errs {
+			log.Printf("startup gc: %s", err)
+		}
+	}
+
 	return nil
 }
 
-// Run starts the extension, listening for JSON-RPC messages on stdin/stdout
+// kubeconfigLoadingRules returns the standard clientcmd loading rules, which
+// merge the files listed in KUBECONFIG or fall back to ~/.kube/config. An
+// explicit kubeconfig path takes precedence and must exist. When no kubeconfig
+// file exists, nil rules select the in-cluster configuration.
+func kubeconfigLoadingRules(kubeconfigPath string) (*clientcmd.ClientConfigLoadingRules, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfigPath != "" {
+		if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("kubeconfig not found: %s", kubeconfigPath)
+		}
+		loadingRules.ExplicitPath = kubeconfigPath
+		return loadingRules, nil
+	}
+
+	for _, path := range loadingRules.GetLoadingPrecedence() {
+		if _, err := os.Stat(path); err == nil {
+			return loadingRules, nil
+		}
+	}
+	return nil, nil
+}
+
+// expandHome replaces a leading ~ in path with the user's home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// Run starts the extension, listening for JSON-RPC messages on stdin/stdout.
+// When cleanupOnShutdown is configured, tracked resources and generated namespaces
+// are deleted after the context is cancelled, stdin closes or shutdown is requested.
+// Modified kubeconfig files are always restored, before the cleanup that may take
+// long, and temporary files holding credentials are always removed.
 func (e *Extension) Run(ctx context.Context) error {
-	return e.Extension.Run(ctx)
+	err := e.Extension.Run(ctx)
+	e.restoreKubeconfigOnShutdown()
+	if e.cleanupOnShutdown {
+		e.shutdownCleanup()
+	}
+	e.removeTempFiles()
+	return err
 }
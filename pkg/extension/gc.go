@@ -0,0 +1,173 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// generatedLabel marks namespaces created by createNamespace in any run.
+	generatedLabel = "mcpchecker.io/generated"
+	// ownerRunAnnotation records the run ID that created a generated namespace.
+	ownerRunAnnotation = "mcpchecker.io/owner-run-id"
+	// expiresAtAnnotation records when a generated namespace may be garbage collected (RFC 3339).
+	expiresAtAnnotation = "mcpchecker.io/expires-at"
+
+	// defaultNamespaceTTL is how long generated namespaces live before gcStale may delete them.
+	defaultNamespaceTTL = 24 * time.Hour
+	// defaultGCTimeout bounds the garbage collection pass run at startup.
+	defaultGCTimeout = 60 * time.Second
+)
+
+func (e *Extension) namespaceTTLOrDefault() time.Duration {
+	if e.namespaceTTL > 0 {
+		return e.namespaceTTL
+	}
+	return defaultNamespaceTTL
+}
+
+// applyGCAnnotations records the owner run and expiry time on a generated namespace.
+func (e *Extension) applyGCAnnotations(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if e.runID != "" {
+		annotations[ownerRunAnnotation] = e.runID
+	}
+	annotations[expiresAtAnnotation] = time.Now().Add(e.namespaceTTLOrDefault()).UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// gcResult summarizes a garbage collection pass.
+type gcResult struct {
+	namespaces []string
+	resources  int
+	errs       []string
+}
+
+// gcStale deletes expired generated namespaces of earlier runs, found through their
+// labels and annotations in the selected kubeconfig context, and expired entries of
+// earlier runs in the state file, each in the context it was created in.
+func (e *Extension) gcStale(ctx context.Context) gcResult {
+	var result gcResult
+	now := time.Now()
+	seen := make(map[string]bool)
+
+	list, err := e.kube(ctx).List(ctx, namespaceGVR, "", metav1.ListOptions{LabelSelector: generatedLabel + "=true"})
+	if err != nil {
+		result.errs = append(result.errs, fmt.Sprintf("failed to list generated namespaces: %s", err.Error()))
+	} else {
+		var expired []string
+		contexts := make(map[string]string)
+		for _, ns := range list.Items {
+			annotations := ns.GetAnnotations()
+			if e.runID != "" && annotations[ownerRunAnnotation] == e.runID {
+				continue
+			}
+			expiresAt, err := time.Parse(time.RFC3339, annotations[expiresAtAnnotation])
+			if err != nil || expiresAt.After(now) {
+				continue
+			}
+			expired = append(expired, ns.GetName())
+			contexts[ns.GetName()] = kubeContextName(ctx)
+			seen[ns.GetName()] = true
+		}
+		_, nsErrs := e.deleteNamespaces(ctx, expired, contexts)
+		result.errs = append(result.errs, nsErrs...)
+		result.namespaces = append(result.namespaces, expired...)
+	}
+
+	if e.stateFile == "" {
+		return result
+	}
+
+	state, err := loadState(e.stateFile)
+	if err != nil {
+		result.errs = append(result.errs, err.Error())
+		return result
+	}
+
+	var collected []string
+	for runID, run := range state.Runs {
+		if runID == e.runID || run.ExpiresAt.After(now) {
+			continue
+		}
+
+		resources := make([]trackedResource, 0, len(run.Resources))
+		for _, r := range run.Resources {
+			resources = append(resources, r.tracked())
+		}
+		deleted, _, leftovers, errs := e.deleteTrackedResources(ctx, resources, false, 0)
+		result.resources += deleted
+		result.errs = append(result.errs, errs...)
+
+		var namespaces []string
+		for _, ns := range run.Namespaces {
+			if !seen[ns] {
+				namespaces = append(namespaces, ns)
+				seen[ns] = true
+			}
+		}
+		_, nsErrs := e.deleteNamespaces(ctx, namespaces, run.NamespaceContexts)
+		result.errs = append(result.errs, nsErrs...)
+		result.namespaces = append(result.namespaces, namespaces...)
+
+		if len(leftovers) == 0 && len(nsErrs) == 0 {
+			collected = append(collected, runID)
+		}
+	}
+
+	if err := e.removeStateRuns(collected); err != nil {
+		result.errs = append(result.errs, err.Error())
+	}
+
+	sort.Strings(result.namespaces)
+	return result
+}
+
+// handleGCStale deletes namespaces and resources left behind by earlier runs
+// whose expiry time has passed.
+func (e *Extension) handleGCStale(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	e.LogInfo(ctx, "Collecting stale namespaces and resources", map[string]any{
+		"runId":     e.runID,
+		"stateFile": e.stateFile,
+	})
+
+	result := e.gcStale(ctx)
+
+	outputs := map[string]string{
+		"namespaces": strings.Join(result.namespaces, ","),
+		"resources":  fmt.Sprintf("%d", result.resources),
+	}
+
+	if len(result.errs) > 0 {
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("Collected %d stale namespace(s) and %d resource(s) with errors", len(result.namespaces), result.resources),
+			Error:   fmt.Sprintf("failed to collect stale resources: %s", strings.Join(result.errs, "; ")),
+			Outputs: outputs,
+		}, nil
+	}
+
+	e.LogInfo(ctx, "Stale resources collected", map[string]any{
+		"namespaces": result.namespaces,
+		"resources":  result.resources,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Collected %d stale namespace(s) and %d resource(s)", len(result.namespaces), result.resources),
+		outputs,
+	), nil
+}
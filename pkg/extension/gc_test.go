@@ -0,0 +1,251 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func generatedNamespace(name, owner string, expiresAt time.Time) unstructured.Unstructured {
+	ns := unstructured.Unstructured{Object: map[string]any{}}
+	ns.SetName(name)
+	ns.SetLabels(map[string]string{generatedLabel: "true"})
+	ns.SetAnnotations(map[string]string{
+		ownerRunAnnotation:  owner,
+		expiresAtAnnotation: expiresAt.UTC().Format(time.RFC3339),
+	})
+	return ns
+}
+
+func TestApplyGCAnnotations(t *testing.T) {
+	ext := &Extension{runID: "run1", namespaceTTL: time.Hour}
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	obj.SetAnnotations(map[string]string{"keep": "me"})
+
+	ext.applyGCAnnotations(obj)
+
+	annotations := obj.GetAnnotations()
+	if annotations["keep"] != "me" {
+		t.Errorf("expected existing annotations kept, got %v", annotations)
+	}
+	if annotations[ownerRunAnnotation] != "run1" {
+		t.Errorf("owner annotation = %q, want %q", annotations[ownerRunAnnotation], "run1")
+	}
+	expiresAt, err := time.Parse(time.RFC3339, annotations[expiresAtAnnotation])
+	if err != nil {
+		t.Fatalf("invalid expiry annotation: %v", err)
+	}
+	if d := time.Until(expiresAt); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("expected expiry about one hour from now, got %s", d)
+	}
+}
+
+func TestHandleGCStale(t *testing.T) {
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		clientNil      bool
+		namespaces     []unstructured.Unstructured
+		state          *persistedState
+		wantSuccess    bool
+		wantDeleted    []string
+		wantOutputs    map[string]string
+		wantStateRuns  []string
+		wantErrContain string
+	}{
+		{
+			name:           "client not initialized",
+			clientNil:      true,
+			wantSuccess:    false,
+			wantErrContain: "kubernetes client not initialized",
+		},
+		{
+			name: "expired namespaces of other runs are deleted",
+			namespaces: []unstructured.Unstructured{
+				generatedNamespace("test-old", "run0", past),
+				generatedNamespace("test-fresh", "run0", future),
+				generatedNamespace("test-own", "run1", past),
+			},
+			wantSuccess: true,
+			wantDeleted: []string{"namespaces/test-old"},
+			wantOutputs: map[string]string{"namespaces": "test-old", "resources": "0"},
+		},
+		{
+			name: "namespaces without valid expiry are kept",
+			namespaces: []unstructured.Unstructured{
+				func() unstructured.Unstructured {
+					ns := generatedNamespace("test-broken", "run0", past)
+					ns.SetAnnotations(map[string]string{ownerRunAnnotation: "run0", expiresAtAnnotation: "soon"})
+					return ns
+				}(),
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{"namespaces": "", "resources": "0"},
+		},
+		{
+			name: "expired state file runs are collected and removed",
+			namespaces: []unstructured.Unstructured{
+				generatedNamespace("test-old", "run0", past),
+			},
+			state: &persistedState{Runs: map[string]*persistedRun{
+				"run0": {
+					ExpiresAt:  past,
+					Namespaces: []string{"test-old", "test-gone"},
+					Resources: []persistedResource{
+						{Version: "v1", Resource: "configmaps", Kind: "ConfigMap", Namespace: "default", Name: "cm", UID: "uid"},
+					},
+				},
+				"run2": {ExpiresAt: future, Namespaces: []string{"test-later"}},
+				"run1": {ExpiresAt: past, Namespaces: []string{"test-own"}},
+			}},
+			wantSuccess:   true,
+			wantDeleted:   []string{"namespaces/test-old", "configmaps/cm", "namespaces/test-gone"},
+			wantOutputs:   map[string]string{"namespaces": "test-gone,test-old", "resources": "1"},
+			wantStateRuns: []string{"run1", "run2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			client := &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if gvr != namespaceGVR {
+						t.Errorf("unexpected list of %s", gvr.Resource)
+					}
+					if opts.LabelSelector != generatedLabel+"=true" {
+						t.Errorf("label selector = %q, want %q", opts.LabelSelector, generatedLabel+"=true")
+					}
+					return &unstructured.UnstructuredList{Items: tt.namespaces}, nil
+				},
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					return objectWithUID("uid"), nil
+				},
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					if gvr != namespaceGVR && gvr != configMapGVR {
+						t.Errorf("unexpected delete of %s", gvr.Resource)
+					}
+					deleted = append(deleted, gvr.Resource+"/"+name)
+					return nil
+				},
+			}
+
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+				runID:     "run1",
+			}
+			if tt.clientNil {
+				ext.client = nil
+			}
+			if tt.state != nil {
+				ext.stateFile = filepath.Join(t.TempDir(), "state.json")
+				if err := writeState(ext.stateFile, tt.state); err != nil {
+					t.Fatalf("failed to write state: %v", err)
+				}
+			}
+
+			result, err := ext.handleGCStale(context.Background(), &sdk.OperationRequest{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Success != tt.wantSuccess {
+				t.Errorf("expected success=%v, got success=%v (error: %s)", tt.wantSuccess, result.Success, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if !slices.Equal(deleted, tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("output %s = %q, want %q", k, got, want)
+				}
+			}
+
+			if tt.state != nil {
+				state, err := loadState(ext.stateFile)
+				if err != nil {
+					t.Fatalf("failed to load state: %v", err)
+				}
+				var runs []string
+				for id := range state.Runs {
+					runs = append(runs, id)
+				}
+				slices.Sort(runs)
+				if !slices.Equal(runs, tt.wantStateRuns) {
+					t.Errorf("state runs = %v, want %v", runs, tt.wantStateRuns)
+				}
+			}
+		})
+	}
+}
+
+func TestSharedStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	// Each extension stands for a separate process sharing the state file, so
+	// only the lock file serializes their updates.
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ext := &Extension{
+				runID:               fmt.Sprintf("run%d", i),
+				stateFile:           stateFile,
+				generatedNamespaces: []string{fmt.Sprintf("ns-%d", i)},
+			}
+			for range 5 {
+				if err := ext.saveState(); err != nil {
+					t.Errorf("saveState: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	state, err := loadState(stateFile)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if len(state.Runs) != 8 {
+		t.Errorf("state has %d runs, want 8", len(state.Runs))
+	}
+	if _, err := os.Stat(stateFile + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("expected the lock to be released, got %v", err)
+	}
+}
+
+func TestStaleStateLock(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	lockPath := stateFile + ".lock"
+	if err := os.WriteFile(lockPath, nil, 0o600); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+	stale := time.Now().Add(-2 * staleStateLockAge)
+	if err := os.Chtimes(lockPath, stale, stale); err != nil {
+		t.Fatalf("failed to age lock: %v", err)
+	}
+
+	ext := &Extension{runID: "run1", stateFile: stateFile, generatedNamespaces: []string{"ns"}}
+	if err := ext.saveState(); err != nil {
+		t.Fatalf("expected a stale lock to be taken over: %v", err)
+	}
+}
@@ -41,9 +41,7 @@ func (e *Extension) handleHelmInstall(ctx context.Context, req *sdk.OperationReq
 		cmdArgs = append(cmdArgs, "--namespace", namespace)
 	}
 
-	if e.kubeconfigPath != "" {
-		cmdArgs = append(cmdArgs, "--kubeconfig", e.kubeconfigPath)
-	}
+	cmdArgs = append(cmdArgs, e.helmKubeArgs(ctx)...)
 
 	// Add values as --set flags
 	for k, v := range values {
@@ -92,9 +90,7 @@ func (e *Extension) handleHelmList(ctx context.Context, req *sdk.OperationReques
 		cmdArgs = append(cmdArgs, "--namespace", namespace)
 	}
 
-	if e.kubeconfigPath != "" {
-		cmdArgs = append(cmdArgs, "--kubeconfig", e.kubeconfigPath)
-	}
+	cmdArgs = append(cmdArgs, e.helmKubeArgs(ctx)...)
 
 	e.LogInfo(ctx, "Listing Helm releases", map[string]any{
 		"namespace":      namespace,
@@ -155,9 +151,7 @@ func (e *Extension) handleHelmUninstall(ctx context.Context, req *sdk.OperationR
 		cmdArgs = append(cmdArgs, "--namespace", namespace)
 	}
 
-	if e.kubeconfigPath != "" {
-		cmdArgs = append(cmdArgs, "--kubeconfig", e.kubeconfigPath)
-	}
+	cmdArgs = append(cmdArgs, e.helmKubeArgs(ctx)...)
 
 	e.LogInfo(ctx, "Uninstalling Helm release", map[string]any{
 		"name":      name,
@@ -187,3 +181,20 @@ func (e *Extension) handleHelmUninstall(ctx context.Context, req *sdk.OperationR
 
 	return sdk.Success(fmt.Sprintf("Helm release '%s' uninstalled successfully\n%s", name, string(output))), nil
 }
+
+// helmKubeArgs returns the flags selecting the kubeconfig and the context an
+// operation runs against.
+func (e *Extension) helmKubeArgs(ctx context.Context) []string {
+	var args []string
+	if e.kubeconfigPath != "" {
+		args = append(args, "--kubeconfig", e.kubeconfigPath)
+	}
+	kubeContext := kubeContextName(ctx)
+	if kubeContext == "" {
+		kubeContext = e.kubeContext
+	}
+	if kubeContext != "" {
+		args = append(args, "--kube-context", kubeContext)
+	}
+	return args
+}
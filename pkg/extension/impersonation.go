@@ -0,0 +1,104 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+)
+
+// impersonationKeys are the arguments that select the subject an operation is
+// performed as. They are not part of the resource spec passed to create.
+var impersonationKeys = []string{"as", "asGroups", "asUID", "expectForbidden"}
+
+// parseImpersonation reads the as, asGroups and asUID arguments. Kubernetes
+// only allows groups and a UID to be impersonated together with a user.
+func parseImpersonation(args map[string]any) (rest.ImpersonationConfig, error) {
+	var impersonate rest.ImpersonationConfig
+	impersonate.UserName, _ = args["as"].(string)
+	impersonate.UID, _ = args["asUID"].(string)
+
+	groups, err := stringListArg(args, "asGroups")
+	if err != nil {
+		return impersonate, err
+	}
+	impersonate.Groups = groups
+
+	if impersonate.UserName == "" && (len(impersonate.Groups) > 0 || impersonate.UID != "") {
+		return impersonate, fmt.Errorf("asGroups and asUID require as")
+	}
+	return impersonate, nil
+}
+
+// clientAs returns a client that sends requests as the given subject, or the
+// extension's own client for the selected context when no user is impersonated.
+func (e *Extension) clientAs(ctx context.Context, impersonate rest.ImpersonationConfig) (ResourceClient, error) {
+	if impersonate.UserName == "" {
+		return e.kube(ctx), nil
+	}
+	return e.kube(ctx).Impersonate(impersonate)
+}
+
+// actor is the client an operation sends its own requests with, and the
+// subject it impersonates. Follow-up requests the extension makes on its own
+// behalf, such as inspecting a deleted namespace, use the extension's client.
+type actor struct {
+	client          ResourceClient
+	as              string
+	expectForbidden bool
+}
+
+// actorFor reads the as, asGroups, asUID and expectForbidden arguments.
+func (e *Extension) actorFor(ctx context.Context, args map[string]any) (actor, error) {
+	impersonate, err := parseImpersonation(args)
+	if err != nil {
+		return actor{}, err
+	}
+	client, err := e.clientAs(ctx, impersonate)
+	if err != nil {
+		return actor{}, err
+	}
+	expectForbidden, _ := args["expectForbidden"].(bool)
+	return actor{client: client, as: impersonate.UserName, expectForbidden: expectForbidden}, nil
+}
+
+// forbidden returns the result of action for err when the request was, or was
+// expected to be, forbidden. See forbiddenResult.
+func (a actor) forbidden(err error, action string) *sdk.OperationResult {
+	return forbiddenResult(err, a.expectForbidden, a.as, action)
+}
+
+// forbiddenResult reports the result of a request that was forbidden, or that
+// was expected to be forbidden, so tasks can assert that a subject must not be
+// able to perform an action. It returns nil when err should be handled as usual.
+func forbiddenResult(err error, expectForbidden bool, as, action string) *sdk.OperationResult {
+	who := as
+	if who == "" {
+		who = "the extension"
+	}
+
+	switch {
+	case expectForbidden && apierrors.IsForbidden(err):
+		return sdk.SuccessWithOutputs(
+			fmt.Sprintf("%s is forbidden to %s, as expected", who, action),
+			map[string]string{"forbidden": "true"},
+		)
+	case expectForbidden && err == nil:
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("%s was allowed to %s", who, action),
+			Error:   "expected the request to be forbidden",
+			Outputs: map[string]string{"forbidden": "false"},
+		}
+	case apierrors.IsForbidden(err):
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is forbidden to %s", who, action),
+			Error:   fmt.Sprintf("forbidden: %s", err.Error()),
+			Outputs: map[string]string{"forbidden": "true"},
+		}
+	}
+	return nil
+}
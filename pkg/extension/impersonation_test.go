@@ -0,0 +1,228 @@
+package extension
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+func TestImpersonatedOperations(t *testing.T) {
+	configMap := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "settings", "namespace": "default"},
+	}
+	with := func(base map[string]any, extra map[string]any) map[string]any {
+		m := make(map[string]any, len(base)+len(extra))
+		for k, v := range base {
+			m[k] = v
+		}
+		for k, v := range extra {
+			m[k] = v
+		}
+		return m
+	}
+
+	tests := []struct {
+		name            string
+		op              string
+		args            map[string]any
+		forbidden       bool
+		wantSuccess     bool
+		wantErrContain  string
+		wantImpersonate *rest.ImpersonationConfig
+		wantForbidden   string
+		wantTracked     int
+	}{
+		{
+			name:        "create without impersonation",
+			op:          "create",
+			args:        configMap,
+			wantSuccess: true,
+			wantTracked: 1,
+		},
+		{
+			name:            "create as subject",
+			op:              "create",
+			args:            with(configMap, map[string]any{"as": "alice", "asGroups": []any{"devs"}, "asUID": "42"}),
+			wantSuccess:     true,
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice", UID: "42", Groups: []string{"devs"}},
+			wantTracked:     1,
+		},
+		{
+			name:           "groups without user",
+			op:             "create",
+			args:           with(configMap, map[string]any{"asGroups": []any{"devs"}}),
+			wantErrContain: "asGroups and asUID require as",
+		},
+		{
+			name:            "create forbidden",
+			op:              "create",
+			args:            with(configMap, map[string]any{"as": "alice"}),
+			forbidden:       true,
+			wantErrContain:  "forbidden",
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice"},
+			wantForbidden:   "true",
+		},
+		{
+			name:            "create forbidden as expected",
+			op:              "create",
+			args:            with(configMap, map[string]any{"as": "alice", "expectForbidden": true}),
+			forbidden:       true,
+			wantSuccess:     true,
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice"},
+			wantForbidden:   "true",
+		},
+		{
+			name:            "create allowed but expected forbidden",
+			op:              "create",
+			args:            with(configMap, map[string]any{"as": "alice", "expectForbidden": true}),
+			wantErrContain:  "expected the request to be forbidden",
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice"},
+			wantForbidden:   "false",
+			wantTracked:     1,
+		},
+		{
+			name:            "delete forbidden as expected",
+			op:              "delete",
+			args:            with(configMap, map[string]any{"as": "alice", "expectForbidden": true}),
+			forbidden:       true,
+			wantSuccess:     true,
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice"},
+			wantForbidden:   "true",
+		},
+		{
+			name:            "delete by selector forbidden",
+			op:              "delete",
+			args:            map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "namespace": "default", "labelSelector": "app=web", "as": "alice"},
+			forbidden:       true,
+			wantErrContain:  "forbidden",
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice"},
+			wantForbidden:   "true",
+		},
+		{
+			name:            "createNamespace as subject",
+			op:              "createNamespace",
+			args:            map[string]any{"prefix": "team", "as": "alice"},
+			wantSuccess:     true,
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice"},
+		},
+		{
+			name:            "createNamespace forbidden",
+			op:              "createNamespace",
+			args:            map[string]any{"prefix": "team", "as": "alice"},
+			forbidden:       true,
+			wantErrContain:  "forbidden",
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice"},
+			wantForbidden:   "true",
+		},
+		{
+			name:            "wait forbidden",
+			op:              "wait",
+			args:            with(configMap, map[string]any{"condition": "Ready", "timeout": "5s", "as": "alice"}),
+			forbidden:       true,
+			wantErrContain:  "forbidden",
+			wantImpersonate: &rest.ImpersonationConfig{UserName: "alice"},
+			wantForbidden:   "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forbidden := func(gvr schema.GroupVersionResource, name string) error {
+				if tt.forbidden {
+					return apierrors.NewForbidden(gvr.GroupResource(), name, nil)
+				}
+				return nil
+			}
+
+			var created []*unstructured.Unstructured
+			subject := &mockClient{
+				createFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+					if err := forbidden(gvr, obj.GetName()); err != nil {
+						return nil, err
+					}
+					created = append(created, obj)
+					return obj, nil
+				},
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					return nil, forbidden(gvr, name)
+				},
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if err := forbidden(gvr, ""); err != nil {
+						return nil, err
+					}
+					return &unstructured.UnstructuredList{}, nil
+				},
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					return forbidden(gvr, name)
+				},
+			}
+
+			var impersonated *rest.ImpersonationConfig
+			base := subject
+			if tt.wantImpersonate != nil || tt.wantErrContain == "asGroups and asUID require as" {
+				base = &mockClient{}
+			}
+			base.impersonateFn = func(impersonate rest.ImpersonationConfig) (ResourceClient, error) {
+				impersonated = &impersonate
+				return subject, nil
+			}
+
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    base,
+			}
+
+			handlers := map[string]sdk.OperationHandler{
+				"create":          ext.handleCreate,
+				"delete":          ext.handleDelete,
+				"wait":            ext.handleWait,
+				"createNamespace": ext.handleCreateNamespace,
+			}
+			result, err := handlers[tt.op](context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if got := result.Outputs["forbidden"]; got != tt.wantForbidden {
+				t.Errorf("forbidden output = %q, want %q", got, tt.wantForbidden)
+			}
+
+			switch {
+			case tt.wantImpersonate == nil && impersonated != nil:
+				t.Errorf("unexpected impersonation of %+v", impersonated)
+			case tt.wantImpersonate != nil && impersonated == nil:
+				t.Errorf("expected impersonation of %+v", tt.wantImpersonate)
+			case tt.wantImpersonate != nil:
+				if impersonated.UserName != tt.wantImpersonate.UserName || impersonated.UID != tt.wantImpersonate.UID || !slices.Equal(impersonated.Groups, tt.wantImpersonate.Groups) {
+					t.Errorf("impersonated %+v, want %+v", impersonated, tt.wantImpersonate)
+				}
+			}
+
+			for _, obj := range created {
+				for _, key := range impersonationKeys {
+					if _, ok := obj.Object[key]; ok {
+						t.Errorf("created object must not contain %q", key)
+					}
+				}
+			}
+			if tt.op == "create" && len(ext.trackedResources) != tt.wantTracked {
+				t.Errorf("tracked %d resource(s), want %d", len(ext.trackedResources), tt.wantTracked)
+			}
+		})
+	}
+}
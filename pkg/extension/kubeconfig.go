@@ -25,7 +25,7 @@ func (e *Extension) handleListContexts(ctx context.Context, req *sdk.OperationRe
 
 	e.LogInfo(ctx, "Listing kubeconfig contexts", nil)
 
-	contexts, err := e.client.ListContexts(ctx)
+	contexts, err := e.kube(ctx).ListContexts(ctx)
 	if err != nil {
 		e.LogError(ctx, "Failed to list contexts", map[string]any{
 			"error": err.Error(),
@@ -69,7 +69,7 @@ func (e *Extension) handleGetCurrentContext(ctx context.Context, req *sdk.Operat
 
 	e.LogInfo(ctx, "Getting current kubeconfig context", nil)
 
-	currentContext, err := e.client.GetCurrentContext(ctx)
+	currentContext, err := e.kube(ctx).GetCurrentContext(ctx)
 	if err != nil {
 		e.LogError(ctx, "Failed to get current context", map[string]any{
 			"error": err.Error(),
@@ -115,7 +115,7 @@ func (e *Extension) handleViewConfig(ctx context.Context, req *sdk.OperationRequ
 		"minify": minify,
 	})
 
-	configYAML, err := e.client.ViewConfig(ctx, minify)
+	configYAML, err := e.kube(ctx).ViewConfig(ctx, minify)
 	if err != nil {
 		e.LogError(ctx, "Failed to view config", map[string]any{
 			"error": err.Error(),
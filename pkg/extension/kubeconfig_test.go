@@ -3,9 +3,13 @@ package extension
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 func TestHandleListContexts(t *testing.T) {
@@ -275,3 +279,145 @@ func TestHandleViewConfig(t *testing.T) {
 		})
 	}
 }
+
+// writeKubeconfig writes a kubeconfig with a single context, named after its
+// cluster and user, to dir.
+func writeKubeconfig(t *testing.T, dir, name, current string) string {
+	t.Helper()
+	content := `apiVersion: v1
+kind: Config
+current-context: ` + current + `
+clusters:
+- name: ` + name + `
+  cluster:
+    server: https://` + name + `.example.com
+    certificate-authority: ca-` + name + `.crt
+users:
+- name: ` + name + `
+  user:
+    token: token-` + name + `
+contexts:
+- name: ` + name + `
+  context:
+    cluster: ` + name + `
+    user: ` + name + `
+    namespace: ns-` + name + `
+`
+	path := filepath.Join(dir, name+".yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestMergedKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKubeconfig(t, dir, "alpha", "alpha")
+	second := writeKubeconfig(t, dir, "beta", "beta")
+
+	client := &dynamicClientAdapter{
+		loadingRules: &clientcmd.ClientConfigLoadingRules{Precedence: []string{first, second}},
+	}
+	ctx := context.Background()
+
+	contexts, err := client.ListContexts(ctx)
+	if err != nil {
+		t.Fatalf("ListContexts: %v", err)
+	}
+	if len(contexts) != 2 || contexts[0].Name != "alpha" || !contexts[0].IsCurrent || contexts[1].Name != "beta" || contexts[1].IsCurrent {
+		t.Errorf("contexts = %+v, want alpha (current) and beta", contexts)
+	}
+
+	// The first file that sets current-context wins.
+	current, err := client.GetCurrentContext(ctx)
+	if err != nil || current != "alpha" {
+		t.Errorf("current context = %q, %v, want alpha", current, err)
+	}
+
+	client.contextName = "beta"
+	raw, err := client.ViewConfig(ctx, true)
+	if err != nil {
+		t.Fatalf("ViewConfig: %v", err)
+	}
+	config, err := clientcmd.Load([]byte(raw))
+	if err != nil {
+		t.Fatalf("invalid kubeconfig: %v", err)
+	}
+	if config.CurrentContext != "beta" || len(config.Contexts) != 1 {
+		t.Errorf("minified config has current context %q and %d contexts, want only beta", config.CurrentContext, len(config.Contexts))
+	}
+	if ca := config.Clusters["beta"].CertificateAuthority; ca != filepath.Join(dir, "ca-beta.crt") {
+		t.Errorf("certificate authority = %q, want it resolved against the kubeconfig directory", ca)
+	}
+}
+
+func TestKubeconfigLoadingRules(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKubeconfig(t, dir, "alpha", "alpha")
+	second := writeKubeconfig(t, dir, "beta", "beta")
+	missing := filepath.Join(dir, "missing.yaml")
+
+	tests := []struct {
+		name           string
+		kubeconfig     string
+		env            string
+		wantErrContain string
+		wantInCluster  bool
+		wantPrecedence []string
+		wantExplicit   string
+	}{
+		{
+			name:           "KUBECONFIG with several files",
+			env:            first + string(os.PathListSeparator) + second,
+			wantPrecedence: []string{first, second},
+		},
+		{
+			name:         "explicit kubeconfig takes precedence",
+			kubeconfig:   second,
+			env:          first,
+			wantExplicit: second,
+		},
+		{
+			name:           "missing explicit kubeconfig",
+			kubeconfig:     missing,
+			wantErrContain: "kubeconfig not found",
+		},
+		{
+			name:          "no kubeconfig file falls back to in-cluster",
+			env:           missing,
+			wantInCluster: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(clientcmd.RecommendedConfigPathEnvVar, tt.env)
+
+			rules, err := kubeconfigLoadingRules(tt.kubeconfig)
+			if tt.wantErrContain != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrContain) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErrContain, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantInCluster {
+				if rules != nil {
+					t.Errorf("expected in-cluster configuration, got rules for %v", rules.GetLoadingPrecedence())
+				}
+				return
+			}
+			if rules == nil {
+				t.Fatal("expected loading rules, got in-cluster configuration")
+			}
+			if tt.wantExplicit != "" && rules.GetExplicitFile() != tt.wantExplicit {
+				t.Errorf("explicit file = %q, want %q", rules.GetExplicitFile(), tt.wantExplicit)
+			}
+			if tt.wantPrecedence != nil && strings.Join(rules.GetLoadingPrecedence(), ",") != strings.Join(tt.wantPrecedence, ",") {
+				t.Errorf("precedence = %v, want %v", rules.GetLoadingPrecedence(), tt.wantPrecedence)
+			}
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfigBackupSuffix is appended to a kubeconfig file's path to name the
+// copy of its original content. The copy lets the file be restored by hand
+// when the extension stops without restoring it.
+const kubeconfigBackupSuffix = ".mcpchecker-backup"
+
+// kubeconfigBackup is the original state of a kubeconfig file that the
+// extension may modify.
+type kubeconfigBackup struct {
+	path    string
+	existed bool
+	content []byte
+	mode    fs.FileMode
+}
+
+// modifyKubeconfig applies change to the merged kubeconfig and writes the
+// modified stanzas back to the files they were loaded from. The files are
+// backed up before the first modification so restoreKubeconfig can put them back.
+func (e *Extension) modifyKubeconfig(change func(config *clientcmdapi.Config) error) error {
+	if e.loadingRules == nil {
+		return fmt.Errorf("no kubeconfig file is loaded, the extension uses the in-cluster configuration")
+	}
+
+	e.kubeconfigMu.Lock()
+	defer e.kubeconfigMu.Unlock()
+
+	config, err := e.loadingRules.GetStartingConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	if err := change(config); err != nil {
+		return err
+	}
+
+	if e.kubeconfigBackups == nil {
+		backups, err := backupKubeconfigFiles(e.loadingRules.GetLoadingPrecedence())
+		if err != nil {
+			return err
+		}
+		e.kubeconfigBackups = backups
+	}
+
+	if err := clientcmd.ModifyConfig(e.loadingRules, *config, true); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return nil
+}
+
+// backupKubeconfigFiles records the content of the given kubeconfig files and
+// copies existing ones next to the original. An existing copy is left by a run
+// that did not restore the file, and is not overwritten. When a file cannot be
+// backed up, the copies already written are removed again.
+func backupKubeconfigFiles(paths []string) (_ []kubeconfigBackup, err error) {
+	var written []string
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, path := range written {
+			if removeErr := os.Remove(path); removeErr != nil && !os.IsNotExist(removeErr) {
+				err = errors.Join(err, fmt.Errorf("failed to remove partial backup: %w", removeErr))
+			}
+		}
+	}()
+
+	backups := make([]kubeconfigBackup, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			backups = append(backups, kubeconfigBackup{path: path})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up kubeconfig: %w", err)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up kubeconfig: %w", err)
+		}
+		backupPath := path + kubeconfigBackupSuffix
+		if _, err := os.Stat(backupPath); err == nil {
+			return nil, fmt.Errorf("kubeconfig backup %s already exists, restore or remove it first", backupPath)
+		}
+		if err := os.WriteFile(backupPath, content, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to back up kubeconfig: %w", err)
+		}
+		written = append(written, backupPath)
+		backups = append(backups, kubeconfigBackup{path: path, existed: true, content: content, mode: info.Mode().Perm()})
+	}
+	return backups, nil
+}
+
+// restoreKubeconfig puts back the kubeconfig files backed up before the first
+// modification and removes files the modifications created. It returns the
+// restored paths.
+func (e *Extension) restoreKubeconfig() ([]string, error) {
+	e.kubeconfigMu.Lock()
+	defer e.kubeconfigMu.Unlock()
+
+	var restored []string
+	var errs []error
+	var remaining []kubeconfigBackup
+	for _, b := range e.kubeconfigBackups {
+		if !b.existed {
+			err := os.Remove(b.path)
+			switch {
+			case err == nil:
+				restored = append(restored, b.path)
+			case !os.IsNotExist(err):
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", b.path, err))
+				remaining = append(remaining, b)
+			}
+			continue
+		}
+
+		if err := os.WriteFile(b.path, b.content, b.mode); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", b.path, err))
+			remaining = append(remaining, b)
+			continue
+		}
+		if err := os.Chmod(b.path, b.mode); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore mode of %s: %w", b.path, err))
+		}
+		if err := os.Remove(b.path + kubeconfigBackupSuffix); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove backup of %s: %w", b.path, err))
+		}
+		restored = append(restored, b.path)
+	}
+
+	// Files that could not be restored stay backed up so a later restore can retry.
+	e.kubeconfigBackups = remaining
+	return restored, errors.Join(errs...)
+}
+
+// restoreKubeconfigOnShutdown restores modified kubeconfig files when the
+// extension stops.
+func (e *Extension) restoreKubeconfigOnShutdown() {
+	restored, err := e.restoreKubeconfig()
+	for _, path := range restored {
+		log.Printf("shutdown cleanup: restored kubeconfig %s", path)
+	}
+	if err != nil {
+		log.Printf("shutdown cleanup: %v", err)
+	}
+}
+
+// kubeconfigContext returns the named context of config.
+func kubeconfigContext(config *clientcmdapi.Config, name string) (*clientcmdapi.Context, error) {
+	kubeContext, ok := config.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", name)
+	}
+	return kubeContext, nil
+}
+
+// handleUseContext sets the current context of the kubeconfig.
+func (e *Extension) handleUseContext(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	name, _ := args["name"].(string)
+	if name == "" {
+		return sdk.Failure(fmt.Errorf("name is required")), nil
+	}
+
+	var previous string
+	err := e.modifyKubeconfig(func(config *clientcmdapi.Config) error {
+		if _, err := kubeconfigContext(config, name); err != nil {
+			return err
+		}
+		previous = config.CurrentContext
+		config.CurrentContext = name
+		return nil
+	})
+	if err != nil {
+		e.LogError(ctx, "Failed to switch context", map[string]any{
+			"context": name,
+			"error":   err.Error(),
+		})
+		return sdk.Failure(err), nil
+	}
+
+	e.LogInfo(ctx, "Switched current context", map[string]any{
+		"context":  name,
+		"previous": previous,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Switched to context %s", name),
+		map[string]string{
+			"context":         name,
+			"previousContext": previous,
+		},
+	), nil
+}
+
+// handleSetContextNamespace sets the default namespace of a kubeconfig
+// context, the current context unless name is given.
+func (e *Extension) handleSetContextNamespace(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	namespace, ok := args["namespace"].(string)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("namespace is required")), nil
+	}
+	name, _ := args["name"].(string)
+
+	var previous string
+	err := e.modifyKubeconfig(func(config *clientcmdapi.Config) error {
+		if name == "" {
+			name = config.CurrentContext
+			if name == "" {
+				return fmt.Errorf("kubeconfig has no current context, name is required")
+			}
+		}
+		kubeContext, err := kubeconfigContext(config, name)
+		if err != nil {
+			return err
+		}
+		previous = kubeContext.Namespace
+		kubeContext.Namespace = namespace
+		return nil
+	})
+	if err != nil {
+		e.LogError(ctx, "Failed to set context namespace", map[string]any{
+			"context":   name,
+			"namespace": namespace,
+			"error":     err.Error(),
+		})
+		return sdk.Failure(err), nil
+	}
+
+	e.LogInfo(ctx, "Set context namespace", map[string]any{
+		"context":   name,
+		"namespace": namespace,
+		"previous":  previous,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Set namespace of context %s to %q", name, namespace),
+		map[string]string{
+			"context":           name,
+			"namespace":         namespace,
+			"previousNamespace": previous,
+		},
+	), nil
+}
+
+// handleAddContext adds a context for an existing cluster and user to the
+// kubeconfig.
+func (e *Extension) handleAddContext(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	name, _ := args["name"].(string)
+	if name == "" {
+		return sdk.Failure(fmt.Errorf("name is required")), nil
+	}
+	cluster, _ := args["cluster"].(string)
+	if cluster == "" {
+		return sdk.Failure(fmt.Errorf("cluster is required")), nil
+	}
+	user, _ := args["user"].(string)
+	namespace, _ := args["namespace"].(string)
+	overwrite, _ := args["overwrite"].(bool)
+
+	err := e.modifyKubeconfig(func(config *clientcmdapi.Config) error {
+		if _, exists := config.Contexts[name]; exists && !overwrite {
+			return fmt.Errorf("context %q already exists", name)
+		}
+		if _, ok := config.Clusters[cluster]; !ok {
+			return fmt.Errorf("cluster %q not found in kubeconfig", cluster)
+		}
+		if _, ok := config.AuthInfos[user]; user != "" && !ok {
+			return fmt.Errorf("user %q not found in kubeconfig", user)
+		}
+
+		kubeContext := clientcmdapi.NewContext()
+		if existing, ok := config.Contexts[name]; ok {
+			// Write the context back to the file it was loaded from.
+			kubeContext.LocationOfOrigin = existing.LocationOfOrigin
+		}
+		kubeContext.Cluster = cluster
+		kubeContext.AuthInfo = user
+		kubeContext.Namespace = namespace
+		config.Contexts[name] = kubeContext
+		return nil
+	})
+	if err != nil {
+		e.LogError(ctx, "Failed to add context", map[string]any{
+			"context": name,
+			"error":   err.Error(),
+		})
+		return sdk.Failure(err), nil
+	}
+
+	e.LogInfo(ctx, "Added context", map[string]any{
+		"context":   name,
+		"cluster":   cluster,
+		"user":      user,
+		"namespace": namespace,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Added context %s", name),
+		map[string]string{
+			"context": name,
+		},
+	), nil
+}
+
+// handleDeleteContext removes a context from the kubeconfig. Like kubectl
+// config delete-context, the current context is left unchanged when it is
+// the deleted one.
+func (e *Extension) handleDeleteContext(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	name, _ := args["name"].(string)
+	if name == "" {
+		return sdk.Failure(fmt.Errorf("name is required")), nil
+	}
+
+	wasCurrent := false
+	err := e.modifyKubeconfig(func(config *clientcmdapi.Config) error {
+		if _, err := kubeconfigContext(config, name); err != nil {
+			return err
+		}
+		wasCurrent = config.CurrentContext == name
+		delete(config.Contexts, name)
+		return nil
+	})
+	if err != nil {
+		e.LogError(ctx, "Failed to delete context", map[string]any{
+			"context": name,
+			"error":   err.Error(),
+		})
+		return sdk.Failure(err), nil
+	}
+
+	e.LogInfo(ctx, "Deleted context", map[string]any{
+		"context":    name,
+		"wasCurrent": wasCurrent,
+	})
+
+	msg := fmt.Sprintf("Deleted context %s", name)
+	if wasCurrent {
+		msg += ", which was the current context"
+	}
+	return sdk.SuccessWithOutputs(msg, map[string]string{
+		"context":    name,
+		"wasCurrent": fmt.Sprintf("%t", wasCurrent),
+	}), nil
+}
+
+// handleRestoreKubeconfig puts back the kubeconfig files as they were before
+// the first useContext, setContextNamespace, addContext or deleteContext.
+func (e *Extension) handleRestoreKubeconfig(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	restored, err := e.restoreKubeconfig()
+	outputs := map[string]string{
+		"restored": fmt.Sprintf("%d", len(restored)),
+	}
+	if err != nil {
+		e.LogError(ctx, "Failed to restore kubeconfig", map[string]any{
+			"error": err.Error(),
+		})
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("Restored %d kubeconfig file(s)", len(restored)),
+			Error:   err.Error(),
+			Outputs: outputs,
+		}, nil
+	}
+
+	if len(restored) == 0 {
+		return sdk.SuccessWithOutputs("Kubeconfig was not modified", outputs), nil
+	}
+
+	e.LogInfo(ctx, "Restored kubeconfig", map[string]any{
+		"files": restored,
+	})
+	return sdk.SuccessWithOutputs(fmt.Sprintf("Restored %d kubeconfig file(s)", len(restored)), outputs), nil
+}
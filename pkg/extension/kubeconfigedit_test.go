@@ -0,0 +1,280 @@
+package extension
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestKubeconfigMutations(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        func(e *Extension) sdk.OperationHandler
+		args           map[string]any
+		wantSuccess    bool
+		wantErrContain string
+		wantOutputs    map[string]string
+		check          func(t *testing.T, config *clientcmdapi.Config)
+	}{
+		{
+			name:        "use context",
+			handler:     func(e *Extension) sdk.OperationHandler { return e.handleUseContext },
+			args:        map[string]any{"name": "beta"},
+			wantSuccess: true,
+			wantOutputs: map[string]string{"context": "beta", "previousContext": "alpha"},
+			check: func(t *testing.T, config *clientcmdapi.Config) {
+				if config.CurrentContext != "beta" {
+					t.Errorf("current context = %q, want beta", config.CurrentContext)
+				}
+			},
+		},
+		{
+			name:           "use unknown context",
+			handler:        func(e *Extension) sdk.OperationHandler { return e.handleUseContext },
+			args:           map[string]any{"name": "missing"},
+			wantSuccess:    false,
+			wantErrContain: `context "missing" not found`,
+		},
+		{
+			name:        "set namespace of current context",
+			handler:     func(e *Extension) sdk.OperationHandler { return e.handleSetContextNamespace },
+			args:        map[string]any{"namespace": "team-a"},
+			wantSuccess: true,
+			wantOutputs: map[string]string{"context": "alpha", "previousNamespace": "ns-alpha"},
+			check: func(t *testing.T, config *clientcmdapi.Config) {
+				if ns := config.Contexts["alpha"].Namespace; ns != "team-a" {
+					t.Errorf("namespace = %q, want team-a", ns)
+				}
+			},
+		},
+		{
+			name:        "set namespace of named context",
+			handler:     func(e *Extension) sdk.OperationHandler { return e.handleSetContextNamespace },
+			args:        map[string]any{"name": "beta", "namespace": "team-b"},
+			wantSuccess: true,
+			check: func(t *testing.T, config *clientcmdapi.Config) {
+				if ns := config.Contexts["beta"].Namespace; ns != "team-b" {
+					t.Errorf("namespace = %q, want team-b", ns)
+				}
+			},
+		},
+		{
+			name:        "add context",
+			handler:     func(e *Extension) sdk.OperationHandler { return e.handleAddContext },
+			args:        map[string]any{"name": "gamma", "cluster": "beta", "user": "alpha", "namespace": "apps"},
+			wantSuccess: true,
+			check: func(t *testing.T, config *clientcmdapi.Config) {
+				c, ok := config.Contexts["gamma"]
+				if !ok || c.Cluster != "beta" || c.AuthInfo != "alpha" || c.Namespace != "apps" {
+					t.Errorf("context gamma = %+v, want cluster beta, user alpha and namespace apps", c)
+				}
+			},
+		},
+		{
+			name:           "add existing context",
+			handler:        func(e *Extension) sdk.OperationHandler { return e.handleAddContext },
+			args:           map[string]any{"name": "beta", "cluster": "alpha"},
+			wantSuccess:    false,
+			wantErrContain: `context "beta" already exists`,
+		},
+		{
+			name:        "overwrite existing context",
+			handler:     func(e *Extension) sdk.OperationHandler { return e.handleAddContext },
+			args:        map[string]any{"name": "beta", "cluster": "alpha", "overwrite": true},
+			wantSuccess: true,
+			check: func(t *testing.T, config *clientcmdapi.Config) {
+				if c := config.Contexts["beta"]; c.Cluster != "alpha" || c.AuthInfo != "" {
+					t.Errorf("context beta = %+v, want cluster alpha and no user", c)
+				}
+			},
+		},
+		{
+			name:           "add context for unknown cluster",
+			handler:        func(e *Extension) sdk.OperationHandler { return e.handleAddContext },
+			args:           map[string]any{"name": "gamma", "cluster": "missing"},
+			wantSuccess:    false,
+			wantErrContain: `cluster "missing" not found`,
+		},
+		{
+			name:        "delete current context",
+			handler:     func(e *Extension) sdk.OperationHandler { return e.handleDeleteContext },
+			args:        map[string]any{"name": "alpha"},
+			wantSuccess: true,
+			wantOutputs: map[string]string{"wasCurrent": "true"},
+			check: func(t *testing.T, config *clientcmdapi.Config) {
+				if _, ok := config.Contexts["alpha"]; ok {
+					t.Error("expected context alpha to be deleted")
+				}
+				if config.CurrentContext != "alpha" {
+					t.Errorf("current context = %q, want it unchanged", config.CurrentContext)
+				}
+			},
+		},
+		{
+			name:           "missing name",
+			handler:        func(e *Extension) sdk.OperationHandler { return e.handleDeleteContext },
+			args:           map[string]any{},
+			wantSuccess:    false,
+			wantErrContain: "name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			first := writeKubeconfig(t, dir, "alpha", "alpha")
+			second := writeKubeconfig(t, dir, "beta", "beta")
+			loadingRules := &clientcmd.ClientConfigLoadingRules{Precedence: []string{first, second}}
+
+			ext := &Extension{
+				Extension:    sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:       &mockClient{},
+				loadingRules: loadingRules,
+			}
+
+			result, err := tt.handler(ext)(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			for key, want := range tt.wantOutputs {
+				if got := result.Outputs[key]; got != want {
+					t.Errorf("output %s = %q, want %q", key, got, want)
+				}
+			}
+
+			if !tt.wantSuccess {
+				if ext.kubeconfigBackups != nil {
+					t.Error("expected no backup for a failed modification")
+				}
+				return
+			}
+
+			config, err := loadingRules.Load()
+			if err != nil {
+				t.Fatalf("failed to load modified kubeconfig: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, config)
+			}
+			if _, err := os.Stat(first + kubeconfigBackupSuffix); err != nil {
+				t.Errorf("expected a backup of %s: %v", first, err)
+			}
+		})
+	}
+}
+
+func TestRestoreKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	path := writeKubeconfig(t, dir, "alpha", "alpha")
+	missing := filepath.Join(dir, "missing.yaml")
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read kubeconfig: %v", err)
+	}
+
+	ext := &Extension{
+		Extension:    sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:       &mockClient{},
+		loadingRules: &clientcmd.ClientConfigLoadingRules{Precedence: []string{path, missing}},
+	}
+	ctx := context.Background()
+
+	run := func(handler sdk.OperationHandler, args map[string]any) *sdk.OperationResult {
+		t.Helper()
+		result, err := handler(ctx, &sdk.OperationRequest{Args: args})
+		if err != nil || !result.Success {
+			t.Fatalf("operation failed: %v %s", err, result.Error)
+		}
+		return result
+	}
+
+	run(ext.handleAddContext, map[string]any{"name": "gamma", "cluster": "alpha", "user": "alpha"})
+	run(ext.handleUseContext, map[string]any{"name": "gamma"})
+	run(ext.handleSetContextNamespace, map[string]any{"namespace": "apps"})
+
+	modified, err := os.ReadFile(path)
+	if err != nil || string(modified) == string(original) {
+		t.Fatalf("expected the kubeconfig to be modified: %v", err)
+	}
+
+	result := run(ext.handleRestoreKubeconfig, nil)
+	if result.Outputs["restored"] != "1" {
+		t.Errorf("restored = %s, want 1", result.Outputs["restored"])
+	}
+
+	restored, err := os.ReadFile(path)
+	if err != nil || string(restored) != string(original) {
+		t.Errorf("kubeconfig was not restored: %v\n%s", err, restored)
+	}
+	if _, err := os.Stat(path + kubeconfigBackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the backup to be removed, got %v", err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to be created, got %v", missing, err)
+	}
+
+	result = run(ext.handleRestoreKubeconfig, nil)
+	if result.Outputs["restored"] != "0" {
+		t.Errorf("second restore restored %s file(s), want 0", result.Outputs["restored"])
+	}
+
+	// The next modification is backed up again.
+	run(ext.handleUseContext, map[string]any{"name": "alpha"})
+	if len(ext.kubeconfigBackups) != 2 {
+		t.Errorf("expected both kubeconfig files to be backed up again, got %d", len(ext.kubeconfigBackups))
+	}
+}
+
+func TestKubeconfigMutationInCluster(t *testing.T) {
+	ext := &Extension{
+		Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:    &mockClient{},
+	}
+	result, err := ext.handleUseContext(context.Background(), &sdk.OperationRequest{Args: map[string]any{"name": "alpha"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "in-cluster") {
+		t.Errorf("expected an in-cluster failure, got %+v", result)
+	}
+}
+
+func TestExistingKubeconfigBackup(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKubeconfig(t, dir, "alpha", "alpha")
+	path := writeKubeconfig(t, dir, "beta", "beta")
+	if err := os.WriteFile(path+kubeconfigBackupSuffix, []byte("left over"), 0o600); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+
+	ext := &Extension{
+		Extension:    sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:       &mockClient{},
+		loadingRules: &clientcmd.ClientConfigLoadingRules{Precedence: []string{first, path}},
+	}
+	result, err := ext.handleSetContextNamespace(context.Background(), &sdk.OperationRequest{Args: map[string]any{"namespace": "apps"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "already exists") {
+		t.Errorf("expected a failure for the existing backup, got %+v", result)
+	}
+	if content, _ := os.ReadFile(path + kubeconfigBackupSuffix); string(content) != "left over" {
+		t.Error("expected the existing backup to be kept")
+	}
+	if _, err := os.Stat(first + kubeconfigBackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the partial backup of %s to be removed, got %v", first, err)
+	}
+}
@@ -0,0 +1,92 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+)
+
+// kubeContextKey is the context.Context key of the kubeconfig context an
+// operation runs against.
+type kubeContextKey struct{}
+
+// kubeContext is a kubeconfig context selected with the context parameter and
+// its client. The default context has an empty name.
+type kubeContext struct {
+	name   string
+	client ResourceClient
+}
+
+// clientForContext returns the client for a kubeconfig context. An empty name,
+// or the context configured at initialization, selects the default client.
+// Other clients are created on first use and cached per context.
+func (e *Extension) clientForContext(name string) (ResourceClient, error) {
+	if name == "" || name == e.kubeContext {
+		return e.client, nil
+	}
+
+	e.contextMu.Lock()
+	defer e.contextMu.Unlock()
+
+	if client, ok := e.contextClients[name]; ok {
+		return client, nil
+	}
+	if e.newContextClient == nil {
+		return nil, fmt.Errorf("context %q is not available", name)
+	}
+	client, err := e.newContextClient(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for context %q: %w", name, err)
+	}
+	if e.contextClients == nil {
+		e.contextClients = make(map[string]ResourceClient)
+	}
+	e.contextClients[name] = client
+	return client, nil
+}
+
+// withKubeContext returns a context in which kube and kubeContextName refer to
+// the named kubeconfig context.
+func (e *Extension) withKubeContext(ctx context.Context, name string) (context.Context, error) {
+	client, err := e.clientForContext(name)
+	if err != nil {
+		return ctx, err
+	}
+	if name == e.kubeContext {
+		name = ""
+	}
+	return context.WithValue(ctx, kubeContextKey{}, kubeContext{name: name, client: client}), nil
+}
+
+// kube returns the client of the kubeconfig context selected for ctx.
+func (e *Extension) kube(ctx context.Context) ResourceClient {
+	if kc, ok := ctx.Value(kubeContextKey{}).(kubeContext); ok {
+		return kc.client
+	}
+	return e.client
+}
+
+// kubeContextName returns the kubeconfig context selected for ctx, or an empty
+// string for the default context.
+func kubeContextName(ctx context.Context) string {
+	kc, _ := ctx.Value(kubeContextKey{}).(kubeContext)
+	return kc.name
+}
+
+// inContext runs handler against the kubeconfig context named by the context
+// argument, if any.
+func (e *Extension) inContext(handler sdk.OperationHandler) sdk.OperationHandler {
+	return func(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+		args, _ := req.Args.(map[string]any)
+		name, _ := args["context"].(string)
+		if name == "" || e.client == nil {
+			return handler(ctx, req)
+		}
+		ctx, err := e.withKubeContext(ctx, name)
+		if err != nil {
+			return sdk.Failure(err), nil
+		}
+		return handler(ctx, req)
+	}
+}
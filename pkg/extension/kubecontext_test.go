@@ -0,0 +1,270 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// recordingClient returns a mock that records the names of created objects
+// and serves them back until they are deleted.
+func recordingClient(created, deleted *[]string) *mockClient {
+	objects := make(map[string]*unstructured.Unstructured)
+	return &mockClient{
+		createFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+			result := obj.DeepCopy()
+			*created = append(*created, result.GetName())
+			objects[result.GetName()] = result
+			return result, nil
+		},
+		getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+			if obj, ok := objects[name]; ok {
+				return obj, nil
+			}
+			return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+		},
+		deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+			if _, ok := objects[name]; !ok {
+				return fmt.Errorf("%s not found in this cluster", name)
+			}
+			delete(objects, name)
+			*deleted = append(*deleted, name)
+			return nil
+		},
+	}
+}
+
+func TestInContext(t *testing.T) {
+	configMap := func(kubeContext string) map[string]any {
+		args := map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": "cm", "namespace": "default"},
+		}
+		if kubeContext != "" {
+			args["context"] = kubeContext
+		}
+		return args
+	}
+
+	tests := []struct {
+		name           string
+		args           map[string]any
+		wantSuccess    bool
+		wantErrContain string
+		wantDefault    int
+		wantOther      int
+	}{
+		{
+			name:        "default context",
+			args:        configMap(""),
+			wantSuccess: true,
+			wantDefault: 1,
+		},
+		{
+			name:        "configured context uses default client",
+			args:        configMap("cluster-a"),
+			wantSuccess: true,
+			wantDefault: 1,
+		},
+		{
+			name:        "other context",
+			args:        configMap("cluster-b"),
+			wantSuccess: true,
+			wantOther:   1,
+		},
+		{
+			name:           "unknown context",
+			args:           configMap("missing"),
+			wantSuccess:    false,
+			wantErrContain: `context "missing"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var defaultCreated, otherCreated, deleted []string
+			other := recordingClient(&otherCreated, &deleted)
+			ext := &Extension{
+				Extension:   sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:      recordingClient(&defaultCreated, &deleted),
+				kubeContext: "cluster-a",
+				newContextClient: func(name string) (ResourceClient, error) {
+					if name != "cluster-b" {
+						return nil, fmt.Errorf("context was not found")
+					}
+					return other, nil
+				},
+			}
+
+			handler := ext.inContext(ext.handleCreate)
+			result, err := handler(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if len(defaultCreated) != tt.wantDefault || len(otherCreated) != tt.wantOther {
+				t.Errorf("created %d in default and %d in other context, want %d and %d",
+					len(defaultCreated), len(otherCreated), tt.wantDefault, tt.wantOther)
+			}
+		})
+	}
+}
+
+func TestContextClientsAreCached(t *testing.T) {
+	calls := 0
+	ext := &Extension{
+		Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:    &mockClient{},
+		newContextClient: func(name string) (ResourceClient, error) {
+			calls++
+			return &mockClient{}, nil
+		},
+	}
+
+	first, err := ext.clientForContext("cluster-b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := ext.clientForContext("cluster-b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second || calls != 1 {
+		t.Errorf("expected one cached client, created %d", calls)
+	}
+	if c, _ := ext.clientForContext(""); c != ext.client {
+		t.Error("expected the default client for an empty context")
+	}
+}
+
+func TestCleanupUsesCreationContext(t *testing.T) {
+	var defaultCreated, otherCreated, defaultDeleted, otherDeleted []string
+	other := recordingClient(&otherCreated, &otherDeleted)
+	ext := &Extension{
+		Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:    recordingClient(&defaultCreated, &defaultDeleted),
+		newContextClient: func(name string) (ResourceClient, error) {
+			return other, nil
+		},
+	}
+	ctx := context.Background()
+
+	run := func(handler sdk.OperationHandler, args map[string]any) {
+		t.Helper()
+		result, err := ext.inContext(handler)(ctx, &sdk.OperationRequest{Args: args})
+		if err != nil || !result.Success {
+			t.Fatalf("operation failed: %v %s", err, result.Error)
+		}
+	}
+
+	run(ext.handleCreateNamespace, map[string]any{"prefix": "a", "context": "cluster-b"})
+	run(ext.handleCreateNamespace, map[string]any{"prefix": "b"})
+	run(ext.handleCreate, map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "cm-b", "namespace": "default"},
+		"context":    "cluster-b",
+	})
+
+	if len(ext.trackedResources) != 1 || ext.trackedResources[0].context != "cluster-b" {
+		t.Fatalf("expected the ConfigMap to be tracked in cluster-b, got %v", ext.trackedResources)
+	}
+
+	run(ext.handleDeleteTracked, map[string]any{})
+	run(ext.handleDeleteGeneratedNamespaces, map[string]any{})
+
+	slices.Sort(otherDeleted)
+	if len(otherDeleted) != 2 || !strings.HasPrefix(otherDeleted[0], "a-") || otherDeleted[1] != "cm-b" {
+		t.Errorf("deleted in cluster-b = %v, want the a- namespace and cm-b", otherDeleted)
+	}
+	if len(defaultDeleted) != 1 || !strings.HasPrefix(defaultDeleted[0], "b-") {
+		t.Errorf("deleted in default context = %v, want the b- namespace", defaultDeleted)
+	}
+	if len(ext.namespaceContexts) != 0 {
+		t.Errorf("expected namespace contexts to be cleared, got %v", ext.namespaceContexts)
+	}
+}
+
+func TestResetNamespaceUsesSelectedContext(t *testing.T) {
+	// cluster returns a mock serving the ConfigMap "settings" in namespace ns
+	// until it is deleted.
+	cluster := func(uid string, deleted *[]string) *mockClient {
+		present := true
+		settings := func() *unstructured.Unstructured {
+			obj := newObject("v1", "ConfigMap", "settings", nil)
+			obj.SetNamespace("ns")
+			obj.SetUID(types.UID(uid))
+			return obj
+		}
+		return &mockClient{
+			discoverResourcesFn: func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+				return []*metav1.APIResourceList{{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list", "delete"}},
+					},
+				}}, nil
+			},
+			listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+				list := &unstructured.UnstructuredList{}
+				if present {
+					list.Items = append(list.Items, *settings())
+				}
+				return list, nil
+			},
+			getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+				if !present {
+					return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+				}
+				return settings(), nil
+			},
+			deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+				if opts.Preconditions != nil && *opts.Preconditions.UID != types.UID(uid) {
+					return apierrors.NewConflict(gvr.GroupResource(), name, fmt.Errorf("UID mismatch"))
+				}
+				present = false
+				*deleted = append(*deleted, name)
+				return nil
+			},
+		}
+	}
+
+	var defaultDeleted, otherDeleted []string
+	other := cluster("uid-b", &otherDeleted)
+	ext := &Extension{
+		Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:    cluster("uid-a", &defaultDeleted),
+		newContextClient: func(name string) (ResourceClient, error) {
+			return other, nil
+		},
+	}
+
+	result, err := ext.inContext(ext.handleResetNamespace)(context.Background(), &sdk.OperationRequest{Args: map[string]any{
+		"namespace": "ns",
+		"context":   "cluster-b",
+	}})
+	if err != nil || !result.Success {
+		t.Fatalf("resetNamespace failed: %v %s", err, result.Error)
+	}
+	if result.Outputs["deleted"] != "1" {
+		t.Errorf("deleted = %s, want 1", result.Outputs["deleted"])
+	}
+	if !slices.Equal(otherDeleted, []string{"settings"}) || len(defaultDeleted) != 0 {
+		t.Errorf("deleted %v in cluster-b and %v in the default context, want settings in cluster-b only", otherDeleted, defaultDeleted)
+	}
+}
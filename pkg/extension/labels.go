@@ -0,0 +1,185 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// runIDLabel identifies the extension run that created an object.
+	runIDLabel = "mcpchecker.io/run-id"
+	// taskLabelKey carries the configured task label of the run that created an object.
+	taskLabelKey = "mcpchecker.io/task"
+)
+
+// applyRunLabels adds the run ID and task labels to an object before it is created.
+func (e *Extension) applyRunLabels(obj *unstructured.Unstructured) {
+	if e.runID == "" && e.taskLabel == "" {
+		return
+	}
+
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	if e.runID != "" {
+		labels[runIDLabel] = e.runID
+	}
+	if e.taskLabel != "" {
+		labels[taskLabelKey] = e.taskLabel
+	}
+	obj.SetLabels(labels)
+}
+
+// handleCleanupByLabel deletes every object carrying the run label, across all
+// listable and deletable resource types, in namespaces and at cluster scope.
+func (e *Extension) handleCleanupByLabel(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	runID, _ := args["runId"].(string)
+	if runID == "" {
+		runID = e.runID
+	}
+	if runID == "" {
+		return sdk.Failure(fmt.Errorf("runId is required when the extension has no run ID")), nil
+	}
+
+	// Validate the label values so they cannot change the meaning of the selector.
+	if errs := validation.IsValidLabelValue(runID); len(errs) > 0 {
+		return sdk.Failure(fmt.Errorf("invalid runId %q: %s", runID, strings.Join(errs, ", "))), nil
+	}
+	selector := fmt.Sprintf("%s=%s", runIDLabel, runID)
+	if task, _ := args["task"].(string); task != "" {
+		if errs := validation.IsValidLabelValue(task); len(errs) > 0 {
+			return sdk.Failure(fmt.Errorf("invalid task %q: %s", task, strings.Join(errs, ", "))), nil
+		}
+		selector += fmt.Sprintf(",%s=%s", taskLabelKey, task)
+	}
+
+	resources, err := e.discoverResources(ctx, "list", "delete")
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	// Namespaced resources first, then cluster-scoped ones, with namespaces last
+	// so that their contents are not deleted twice.
+	sort.SliceStable(resources, func(i, j int) bool {
+		return sweepOrder(resources[i]) < sweepOrder(resources[j])
+	})
+
+	e.LogInfo(ctx, "Sweeping labelled resources", map[string]any{
+		"selector":      selector,
+		"resourceTypes": len(resources),
+	})
+
+	labelledNamespaces := make(map[string]bool)
+	namespaces, err := e.kube(ctx).List(ctx, namespaceGVR, "", metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to list namespaces: %w", err)), nil
+	}
+	for _, ns := range namespaces.Items {
+		labelledNamespaces[ns.GetName()] = true
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	deleteOpts := metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	}
+
+	var deleted int
+	var errs []string
+	for _, r := range resources {
+		list, err := e.kube(ctx).List(ctx, r.gvr, "", metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+				continue
+			}
+			errs = append(errs, fmt.Sprintf("list %s: %s", r.gvr.GroupResource(), err.Error()))
+			continue
+		}
+
+		for _, item := range list.Items {
+			// Objects inside a labelled namespace go away with the namespace.
+			if r.namespaced && labelledNamespaces[item.GetNamespace()] {
+				continue
+			}
+
+			err := e.kube(ctx).Delete(ctx, r.gvr, item.GetName(), item.GetNamespace(), deleteOpts)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				e.LogError(ctx, "Failed to delete labelled resource", map[string]any{
+					"kind":      r.kind,
+					"name":      item.GetName(),
+					"namespace": item.GetNamespace(),
+					"error":     err.Error(),
+				})
+				errs = append(errs, fmt.Sprintf("%s %s: %s", r.kind, namespacedName(item.GetNamespace(), item.GetName()), err.Error()))
+				continue
+			}
+
+			e.LogInfo(ctx, "Deleted labelled resource", map[string]any{
+				"kind":      r.kind,
+				"name":      item.GetName(),
+				"namespace": item.GetNamespace(),
+			})
+			deleted++
+			e.removeTempFilesFor(ctx, r.gvr, item.GetNamespace(), item.GetName())
+		}
+	}
+
+	outputs := map[string]string{
+		"selector": selector,
+		"deleted":  fmt.Sprintf("%d", deleted),
+		"failed":   fmt.Sprintf("%d", len(errs)),
+	}
+
+	if len(errs) > 0 {
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("Deleted %d resource(s) labelled %s, %d failure(s)", deleted, selector, len(errs)),
+			Error:   fmt.Sprintf("failed to clean up labelled resources: %s", strings.Join(errs, "; ")),
+			Outputs: outputs,
+		}, nil
+	}
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Deleted %d resource(s) labelled %s", deleted, selector),
+		outputs,
+	), nil
+}
+
+// sweepOrder sorts namespaced resource types before cluster-scoped ones and namespaces last.
+func sweepOrder(r apiResource) int {
+	switch {
+	case r.gvr == namespaceGVR:
+		return 2
+	case !r.namespaced:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func namespacedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
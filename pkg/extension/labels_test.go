@@ -0,0 +1,194 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestApplyRunLabels(t *testing.T) {
+	tests := []struct {
+		name      string
+		runID     string
+		taskLabel string
+		labels    map[string]string
+		want      map[string]string
+	}{
+		{
+			name: "no run labels configured",
+		},
+		{
+			name:   "run id only, existing labels kept",
+			runID:  "run1",
+			labels: map[string]string{"app": "web"},
+			want:   map[string]string{"app": "web", runIDLabel: "run1"},
+		},
+		{
+			name:      "run id and task label",
+			runID:     "run1",
+			taskLabel: "create-pod",
+			want:      map[string]string{runIDLabel: "run1", taskLabelKey: "create-pod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := &Extension{runID: tt.runID, taskLabel: tt.taskLabel}
+			obj := &unstructured.Unstructured{Object: map[string]any{}}
+			obj.SetLabels(tt.labels)
+
+			ext.applyRunLabels(obj)
+
+			got := obj.GetLabels()
+			if len(got) != len(tt.want) {
+				t.Fatalf("labels = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("label %s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleCleanupByLabel(t *testing.T) {
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	clusterRoleGVR := schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+
+	discovered := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace", Verbs: []string{"list", "delete"}},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+				{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: []string{"create"}},
+			},
+		},
+		{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "clusterroles", Kind: "ClusterRole", Verbs: []string{"list", "delete"}},
+			},
+		},
+	}
+
+	item := func(namespace, name string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]any{}}
+		obj.SetName(name)
+		obj.SetNamespace(namespace)
+		return obj
+	}
+
+	tests := []struct {
+		name         string
+		args         any
+		runID        string
+		deleteErr    error
+		wantSuccess  bool
+		wantSelector string
+		wantDeleted  []string
+	}{
+		{
+			name:         "sweeps current run",
+			args:         map[string]any{},
+			runID:        "run1",
+			wantSuccess:  true,
+			wantSelector: "mcpchecker.io/run-id=run1",
+			wantDeleted:  []string{"configmaps default/cm", "clusterroles /role", "namespaces /swept-ns"},
+		},
+		{
+			name:         "explicit run id and task",
+			args:         map[string]any{"runId": "old-run", "task": "create-pod"},
+			runID:        "run1",
+			wantSuccess:  true,
+			wantSelector: "mcpchecker.io/run-id=old-run,mcpchecker.io/task=create-pod",
+			wantDeleted:  []string{"configmaps default/cm", "clusterroles /role", "namespaces /swept-ns"},
+		},
+		{
+			name:        "no run id",
+			args:        map[string]any{},
+			wantSuccess: false,
+		},
+		{
+			name:        "invalid task",
+			args:        map[string]any{"task": "a,mcpchecker.io/run-id!=run1"},
+			runID:       "run1",
+			wantSuccess: false,
+		},
+		{
+			name:        "invalid run id",
+			args:        map[string]any{"runId": "run1 in (a)"},
+			wantSuccess: false,
+		},
+		{
+			name:        "delete errors are reported",
+			args:        map[string]any{},
+			runID:       "run1",
+			deleteErr:   errors.New("forbidden"),
+			wantSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			client := &mockClient{
+				discoverResourcesFn: func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+					return discovered, nil
+				},
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if tt.wantSelector != "" && opts.LabelSelector != tt.wantSelector {
+						t.Errorf("label selector = %q, want %q", opts.LabelSelector, tt.wantSelector)
+					}
+					switch gvr {
+					case namespaceGVR:
+						return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{item("", "swept-ns")}}, nil
+					case configMapGVR:
+						return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+							item("default", "cm"),
+							item("swept-ns", "inside-namespace"),
+						}}, nil
+					case clusterRoleGVR:
+						return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{item("", "role")}}, nil
+					}
+					t.Errorf("unexpected list of %v", gvr)
+					return &unstructured.UnstructuredList{}, nil
+				},
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					if tt.deleteErr != nil {
+						return tt.deleteErr
+					}
+					deleted = append(deleted, gvr.Resource+" "+namespace+"/"+name)
+					return nil
+				},
+			}
+
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+				runID:     tt.runID,
+			}
+
+			req := &sdk.OperationRequest{Args: tt.args}
+			result, err := ext.handleCleanupByLabel(context.Background(), req)
+
+			if err != nil {
+				t.Fatalf("handleCleanupByLabel() returned error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("handleCleanupByLabel() success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if !slices.Equal(deleted, tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// escalationVerbs let a subject gain permissions it does not hold itself.
+// They are only accepted when an allowed rule names them explicitly.
+var escalationVerbs = []string{"escalate", "bind", "impersonate"}
+
+// privilegeRule is an entry of the allowed set of assertLeastPrivilege.
+type privilegeRule struct {
+	verbs           []string
+	apiGroups       []string
+	resources       []string
+	resourceNames   []string
+	nonResourceURLs []string
+}
+
+// defaultAllowedRules are granted to every authenticated user by the
+// system:basic-user, system:discovery and system:public-info-viewer roles.
+var defaultAllowedRules = []privilegeRule{
+	{
+		verbs:     []string{"create"},
+		apiGroups: []string{"authorization.k8s.io"},
+		resources: []string{"selfsubjectaccessreviews", "selfsubjectrulesreviews"},
+	},
+	{
+		verbs:     []string{"create"},
+		apiGroups: []string{"authentication.k8s.io"},
+		resources: []string{"selfsubjectreviews"},
+	},
+	{
+		verbs: []string{"get"},
+		nonResourceURLs: []string{
+			"/api", "/api/*", "/apis", "/apis/*", "/healthz", "/livez", "/readyz",
+			"/version", "/version/", "/openapi", "/openapi/*",
+			"/.well-known/openid-configuration", "/openid/v1/jwks",
+		},
+	},
+}
+
+// privilegeViolation is a granted rule that exceeds the allowed set.
+type privilegeViolation struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// parsePrivilegeRules reads the allowed argument: a list of objects with
+// verbs and either resources (with optional apiGroups and resourceNames) or nonResourceURLs.
+func parsePrivilegeRules(args map[string]any) ([]privilegeRule, error) {
+	items, ok := args["allowed"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("allowed must be a list")
+	}
+
+	rules := make([]privilegeRule, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("allowed[%d] must be an object", i)
+		}
+
+		var rule privilegeRule
+		var err error
+		for key, dst := range map[string]*[]string{
+			"verbs":           &rule.verbs,
+			"apiGroups":       &rule.apiGroups,
+			"resources":       &rule.resources,
+			"resourceNames":   &rule.resourceNames,
+			"nonResourceURLs": &rule.nonResourceURLs,
+		} {
+			if *dst, err = stringListArg(m, key); err != nil {
+				return nil, fmt.Errorf("allowed[%d]: %w", i, err)
+			}
+		}
+
+		if len(rule.verbs) == 0 {
+			return nil, fmt.Errorf("allowed[%d].verbs is required", i)
+		}
+		if len(rule.resources) == 0 && len(rule.nonResourceURLs) == 0 {
+			return nil, fmt.Errorf("allowed[%d] requires resources or nonResourceURLs", i)
+		}
+		if len(rule.resources) > 0 && len(rule.nonResourceURLs) > 0 {
+			return nil, fmt.Errorf("allowed[%d] cannot combine resources and nonResourceURLs", i)
+		}
+		if len(rule.resources) > 0 && rule.apiGroups == nil {
+			rule.apiGroups = []string{""}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// matchesToken reports whether an allowed list covers a granted value. A
+// wildcard in the allowed list covers any value, except for literal matches
+// which require the value itself to be listed.
+func matchesToken(allowed []string, value string, literal bool) bool {
+	if slices.Contains(allowed, value) {
+		return true
+	}
+	return !literal && slices.Contains(allowed, "*")
+}
+
+// matchesURL reports whether an allowed list covers a non-resource URL. A
+// trailing * matches any URL with that prefix, as in RBAC.
+func matchesURL(allowed []string, url string) bool {
+	for _, a := range allowed {
+		if a == url || a == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "*"); ok && url != "*" && strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// flagged reports whether a granted value needs an explicit allowed entry:
+// wildcards and escalation verbs.
+func flagged(value string, isVerb bool) bool {
+	return value == "*" || (isVerb && slices.Contains(escalationVerbs, value))
+}
+
+// coversResource reports whether any allowed rule permits verb on resource in
+// group for the granted resource names (none meaning every object).
+func coversResource(allowed []privilegeRule, verb, group, resource string, resourceNames []string) bool {
+	for _, rule := range allowed {
+		if len(rule.resources) == 0 {
+			continue
+		}
+		if !matchesToken(rule.verbs, verb, flagged(verb, true)) ||
+			!matchesToken(rule.apiGroups, group, flagged(group, false)) ||
+			!matchesToken(rule.resources, resource, flagged(resource, false)) {
+			continue
+		}
+		if len(rule.resourceNames) == 0 {
+			return true
+		}
+		if len(resourceNames) > 0 && !slices.ContainsFunc(resourceNames, func(name string) bool {
+			return !slices.Contains(rule.resourceNames, name)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// coversNonResource reports whether any allowed rule permits verb on url.
+func coversNonResource(allowed []privilegeRule, verb, url string) bool {
+	for _, rule := range allowed {
+		if len(rule.nonResourceURLs) > 0 && matchesToken(rule.verbs, verb, flagged(verb, true)) && matchesURL(rule.nonResourceURLs, url) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPrivilegeViolations compares the granted rules against the allowed set.
+func findPrivilegeViolations(status *authorizationv1.SubjectRulesReviewStatus, allowed []privilegeRule) []privilegeViolation {
+	var violations []privilegeViolation
+
+	for _, rule := range status.ResourceRules {
+		desc := fmt.Sprintf("%v %s", rule.Verbs, strings.Join(qualifiedResources(rule), ","))
+		if len(rule.ResourceNames) > 0 {
+			desc += fmt.Sprintf(" %v", rule.ResourceNames)
+		}
+
+		var reasons []string
+		for _, verb := range rule.Verbs {
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					if coversResource(allowed, verb, group, resource, rule.ResourceNames) {
+						continue
+					}
+					reasons = append(reasons, resourceViolationReason(verb, group, resource))
+				}
+			}
+		}
+		if len(reasons) > 0 {
+			violations = append(violations, privilegeViolation{Rule: desc, Reason: strings.Join(dedupe(reasons), "; ")})
+		}
+	}
+
+	for _, rule := range status.NonResourceRules {
+		var reasons []string
+		for _, verb := range rule.Verbs {
+			for _, url := range rule.NonResourceURLs {
+				if coversNonResource(allowed, verb, url) {
+					continue
+				}
+				switch {
+				case url == "*":
+					reasons = append(reasons, "wildcard non-resource URL")
+				case verb == "*":
+					reasons = append(reasons, "wildcard verb")
+				default:
+					reasons = append(reasons, fmt.Sprintf("%s %s not allowed", verb, url))
+				}
+			}
+		}
+		if len(reasons) > 0 {
+			violations = append(violations, privilegeViolation{
+				Rule:   fmt.Sprintf("%v %v", rule.Verbs, rule.NonResourceURLs),
+				Reason: strings.Join(dedupe(reasons), "; "),
+			})
+		}
+	}
+
+	return violations
+}
+
+// resourceViolationReason explains why a granted verb on a resource is not
+// allowed, naming wildcards and escalation verbs explicitly.
+func resourceViolationReason(verb, group, resource string) string {
+	switch {
+	case verb == "*":
+		return "wildcard verb"
+	case resource == "*":
+		return "wildcard resource"
+	case group == "*":
+		return "wildcard API group"
+	case slices.Contains(escalationVerbs, verb):
+		return fmt.Sprintf("%s grants privilege escalation", verb)
+	}
+	if group != "" {
+		resource += "." + group
+	}
+	return fmt.Sprintf("%s %s not allowed", verb, resource)
+}
+
+func dedupe(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if !slices.Contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// handleAssertLeastPrivilege impersonates a subject, collects its effective
+// rules in a namespace and fails if any of them goes beyond the allowed set.
+func (e *Extension) handleAssertLeastPrivilege(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	namespace, _ := args["namespace"].(string)
+	if namespace == "" {
+		return sdk.Failure(fmt.Errorf("namespace is required")), nil
+	}
+
+	subject, err := parseAccessSubject(args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	if subject.User == "" {
+		return sdk.Failure(fmt.Errorf("as is required")), nil
+	}
+	impersonate, err := subject.impersonationConfig()
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	allowed, err := parsePrivilegeRules(args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	includeDefaults := true
+	if v, ok := args["includeDefaults"].(bool); ok {
+		includeDefaults = v
+	}
+	if includeDefaults {
+		allowed = append(allowed, defaultAllowedRules...)
+	}
+
+	e.LogInfo(ctx, "Checking least privilege", map[string]any{
+		"namespace": namespace,
+		"as":        subject.User,
+		"allowed":   len(allowed),
+	})
+
+	client, err := e.clientAs(ctx, impersonate)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	status, err := client.ListRules(ctx, namespace)
+	if err != nil {
+		e.LogError(ctx, "Failed to list rules", map[string]any{
+			"error": err.Error(),
+		})
+		return sdk.Failure(fmt.Errorf("failed to list rules: %w", err)), nil
+	}
+
+	violations := findPrivilegeViolations(status, allowed)
+
+	violationsJSON, err := json.Marshal(violations)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to marshal violations: %w", err)), nil
+	}
+	outputs := map[string]string{
+		"namespace":  namespace,
+		"violations": string(violationsJSON),
+		"count":      fmt.Sprintf("%d", len(violations)),
+		"incomplete": fmt.Sprintf("%t", status.Incomplete),
+	}
+
+	if len(violations) > 0 {
+		var table strings.Builder
+		w := tabwriter.NewWriter(&table, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "RULE\tREASON")
+		for _, v := range violations {
+			fmt.Fprintf(w, "%s\t%s\n", v.Rule, v.Reason)
+		}
+		w.Flush()
+
+		e.LogError(ctx, "Subject is over-privileged", map[string]any{
+			"namespace":  namespace,
+			"as":         subject.User,
+			"violations": len(violations),
+		})
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("%s has %d rule(s) in namespace %s beyond the allowed set:\n%s", subject.User, len(violations), namespace, table.String()),
+			Error:   "least privilege assertion failed",
+			Outputs: outputs,
+		}, nil
+	}
+
+	// Rules the authorizer could not enumerate may exceed the allowed set,
+	// so least privilege cannot be asserted.
+	if status.Incomplete {
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("Rules of %s in namespace %s are incomplete: %s", subject.User, namespace, status.EvaluationError),
+			Error:   "least privilege cannot be asserted from incomplete rules",
+			Outputs: outputs,
+		}, nil
+	}
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("%s has no rules in namespace %s beyond the allowed set", subject.User, namespace),
+		outputs,
+	), nil
+}
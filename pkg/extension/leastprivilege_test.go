@@ -0,0 +1,182 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestHandleAssertLeastPrivilege(t *testing.T) {
+	// Rules every authenticated user has.
+	baseline := authorizationv1.SubjectRulesReviewStatus{
+		ResourceRules: []authorizationv1.ResourceRule{
+			{Verbs: []string{"create"}, APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"selfsubjectaccessreviews", "selfsubjectrulesreviews"}},
+		},
+		NonResourceRules: []authorizationv1.NonResourceRule{
+			{Verbs: []string{"get"}, NonResourceURLs: []string{"/api", "/api/*", "/healthz"}},
+		},
+	}
+	withRules := func(rules ...authorizationv1.ResourceRule) *authorizationv1.SubjectRulesReviewStatus {
+		status := baseline
+		status.ResourceRules = append(append([]authorizationv1.ResourceRule{}, baseline.ResourceRules...), rules...)
+		return &status
+	}
+	podReader := []any{
+		map[string]any{"verbs": []any{"get", "list", "watch"}, "resources": []any{"pods"}},
+	}
+
+	tests := []struct {
+		name           string
+		args           any
+		status         *authorizationv1.SubjectRulesReviewStatus
+		clientNil      bool
+		wantSuccess    bool
+		wantErrContain string
+		wantCount      string
+		wantReasons    []string
+	}{
+		{
+			name:           "client not initialized",
+			args:           map[string]any{"namespace": "test", "as": "alice", "allowed": podReader},
+			clientNil:      true,
+			wantErrContain: "kubernetes client not initialized",
+		},
+		{
+			name:           "missing as",
+			args:           map[string]any{"namespace": "test", "allowed": podReader},
+			wantErrContain: "as is required",
+		},
+		{
+			name:           "invalid allowed rule",
+			args:           map[string]any{"namespace": "test", "as": "alice", "allowed": []any{map[string]any{"verbs": []any{"get"}}}},
+			wantErrContain: "allowed[0] requires resources or nonResourceURLs",
+		},
+		{
+			name:        "exactly the allowed rules",
+			args:        map[string]any{"namespace": "test", "as": "alice", "allowed": podReader},
+			status:      withRules(authorizationv1.ResourceRule{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}}),
+			wantSuccess: true,
+			wantCount:   "0",
+		},
+		{
+			name:      "extra verb and resource",
+			args:      map[string]any{"namespace": "test", "as": "alice", "allowed": podReader},
+			status:    withRules(authorizationv1.ResourceRule{Verbs: []string{"get", "delete"}, APIGroups: []string{""}, Resources: []string{"pods", "secrets"}}),
+			wantCount: "1",
+			wantReasons: []string{
+				"delete pods not allowed",
+				"get secrets not allowed",
+			},
+		},
+		{
+			name: "wildcards and escalation verbs",
+			args: map[string]any{"namespace": "test", "as": "alice", "allowed": []any{
+				map[string]any{
+					"verbs":     []any{"get", "list", "delete"},
+					"apiGroups": []any{"", "apps", "rbac.authorization.k8s.io"},
+					"resources": []any{"pods", "deployments", "clusterroles"},
+				},
+			}},
+			status: withRules(
+				authorizationv1.ResourceRule{Verbs: []string{"*"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+				authorizationv1.ResourceRule{Verbs: []string{"get"}, APIGroups: []string{"apps"}, Resources: []string{"*"}},
+				authorizationv1.ResourceRule{Verbs: []string{"bind"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}},
+			),
+			wantCount:   "3",
+			wantReasons: []string{"wildcard verb", "wildcard resource", "bind grants privilege escalation"},
+		},
+		{
+			name: "explicitly allowed escalation verb",
+			args: map[string]any{"namespace": "test", "as": "alice", "allowed": []any{
+				map[string]any{"verbs": []any{"bind"}, "apiGroups": []any{"rbac.authorization.k8s.io"}, "resources": []any{"clusterroles"}, "resourceNames": []any{"view"}},
+			}},
+			status:      withRules(authorizationv1.ResourceRule{Verbs: []string{"bind"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, ResourceNames: []string{"view"}}),
+			wantSuccess: true,
+			wantCount:   "0",
+		},
+		{
+			name: "resource names wider than allowed",
+			args: map[string]any{"namespace": "test", "as": "alice", "allowed": []any{
+				map[string]any{"verbs": []any{"get"}, "resources": []any{"configmaps"}, "resourceNames": []any{"settings"}},
+			}},
+			status:      withRules(authorizationv1.ResourceRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"configmaps"}}),
+			wantCount:   "1",
+			wantReasons: []string{"get configmaps not allowed"},
+		},
+		{
+			name:        "defaults excluded",
+			args:        map[string]any{"namespace": "test", "as": "alice", "allowed": podReader, "includeDefaults": false},
+			status:      withRules(),
+			wantCount:   "2",
+			wantReasons: []string{"create selfsubjectaccessreviews.authorization.k8s.io not allowed", "get /healthz not allowed"},
+		},
+		{
+			name:           "incomplete rules",
+			args:           map[string]any{"namespace": "test", "as": "alice", "allowed": podReader},
+			status:         &authorizationv1.SubjectRulesReviewStatus{Incomplete: true, EvaluationError: "webhook authorizer"},
+			wantCount:      "0",
+			wantErrContain: "incomplete rules",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var impersonated string
+			client := &mockClient{
+				listRulesFn: func(ctx context.Context, namespace string) (*authorizationv1.SubjectRulesReviewStatus, error) {
+					if impersonated != "alice" {
+						t.Errorf("expected rules to be listed as alice, got %q", impersonated)
+					}
+					return tt.status, nil
+				},
+			}
+			client.impersonateFn = func(impersonate rest.ImpersonationConfig) (ResourceClient, error) {
+				impersonated = impersonate.UserName
+				return client, nil
+			}
+
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+			}
+			if tt.clientNil {
+				ext.client = nil
+			}
+
+			result, err := ext.handleAssertLeastPrivilege(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if tt.wantCount != "" && result.Outputs["count"] != tt.wantCount {
+				t.Errorf("count = %q, want %q (violations: %s)", result.Outputs["count"], tt.wantCount, result.Outputs["violations"])
+			}
+
+			var violations []privilegeViolation
+			if tt.wantReasons != nil {
+				if err := json.Unmarshal([]byte(result.Outputs["violations"]), &violations); err != nil {
+					t.Fatalf("invalid violations output: %v", err)
+				}
+			}
+			var reasons []string
+			for _, v := range violations {
+				reasons = append(reasons, v.Reason)
+			}
+			for _, want := range tt.wantReasons {
+				if !strings.Contains(strings.Join(reasons, "\n"), want) {
+					t.Errorf("expected a violation %q, got %v", want, reasons)
+				}
+			}
+		})
+	}
+}
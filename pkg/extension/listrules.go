@@ -0,0 +1,132 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+// impersonationConfig converts the subject of an access request into the
+// impersonation settings of a client. Kubernetes only allows groups, uid and
+// extra to be impersonated together with a user.
+func (r AccessRequest) impersonationConfig() (rest.ImpersonationConfig, error) {
+	if r.User == "" && (len(r.Groups) > 0 || r.UID != "" || len(r.Extra) > 0) {
+		return rest.ImpersonationConfig{}, fmt.Errorf("groups, uid and extra require as")
+	}
+	return rest.ImpersonationConfig{
+		UserName: r.User,
+		UID:      r.UID,
+		Groups:   r.Groups,
+		Extra:    r.Extra,
+	}, nil
+}
+
+// handleListRules returns the rules the extension's identity, or an
+// impersonated subject, is granted in a namespace, using a SelfSubjectRulesReview.
+func (e *Extension) handleListRules(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	namespace, _ := args["namespace"].(string)
+	if namespace == "" {
+		return sdk.Failure(fmt.Errorf("namespace is required")), nil
+	}
+
+	subject, err := parseAccessSubject(args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	impersonate, err := subject.impersonationConfig()
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	e.LogInfo(ctx, "Listing rules", map[string]any{
+		"namespace": namespace,
+		"as":        subject.User,
+		"groups":    subject.Groups,
+	})
+
+	client, err := e.clientAs(ctx, impersonate)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	status, err := client.ListRules(ctx, namespace)
+	if err != nil {
+		e.LogError(ctx, "Failed to list rules", map[string]any{
+			"error": err.Error(),
+		})
+		return sdk.Failure(fmt.Errorf("failed to list rules: %w", err)), nil
+	}
+
+	if status.Incomplete {
+		e.LogWarn(ctx, "Rules list is incomplete", map[string]any{
+			"namespace":       namespace,
+			"evaluationError": status.EvaluationError,
+		})
+	}
+
+	resourceRules, err := json.Marshal(status.ResourceRules)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to marshal resource rules: %w", err)), nil
+	}
+	nonResourceRules, err := json.Marshal(status.NonResourceRules)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to marshal non-resource rules: %w", err)), nil
+	}
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Rules for %s in namespace %s:\n%s", subject.subject(), namespace, formatRules(status)),
+		map[string]string{
+			"namespace":        namespace,
+			"resourceRules":    string(resourceRules),
+			"nonResourceRules": string(nonResourceRules),
+			"incomplete":       fmt.Sprintf("%t", status.Incomplete),
+			"evaluationError":  status.EvaluationError,
+		},
+	), nil
+}
+
+// formatRules renders rules as a table in the style of kubectl auth can-i --list.
+func formatRules(status *authorizationv1.SubjectRulesReviewStatus) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCES\tNON-RESOURCE URLS\tRESOURCE NAMES\tVERBS")
+	for _, rule := range status.ResourceRules {
+		fmt.Fprintf(w, "%s\t[]\t%v\t%v\n", strings.Join(qualifiedResources(rule), ","), rule.ResourceNames, rule.Verbs)
+	}
+	for _, rule := range status.NonResourceRules {
+		fmt.Fprintf(w, "\t%v\t[]\t%v\n", rule.NonResourceURLs, rule.Verbs)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// qualifiedResources returns the resources of a rule qualified with their
+// API group, e.g. "deployments.apps"; core resources are left unqualified.
+func qualifiedResources(rule authorizationv1.ResourceRule) []string {
+	var names []string
+	for _, group := range rule.APIGroups {
+		for _, resource := range rule.Resources {
+			if group == "" {
+				names = append(names, resource)
+			} else {
+				names = append(names, resource+"."+group)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,134 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestHandleListRules(t *testing.T) {
+	rules := &authorizationv1.SubjectRulesReviewStatus{
+		ResourceRules: []authorizationv1.ResourceRule{
+			{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+			{Verbs: []string{"update"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"web"}},
+		},
+		NonResourceRules: []authorizationv1.NonResourceRule{
+			{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		args            any
+		clientNil       bool
+		listErr         error
+		wantSuccess     bool
+		wantErrContain  string
+		wantImpersonate rest.ImpersonationConfig
+		wantOutputs     map[string]string
+		wantMessage     []string
+	}{
+		{
+			name:           "client not initialized",
+			args:           map[string]any{"namespace": "test"},
+			clientNil:      true,
+			wantErrContain: "kubernetes client not initialized",
+		},
+		{
+			name:           "missing namespace",
+			args:           map[string]any{},
+			wantErrContain: "namespace is required",
+		},
+		{
+			name:           "groups without as",
+			args:           map[string]any{"namespace": "test", "groups": []any{"devs"}},
+			wantErrContain: "groups, uid and extra require as",
+		},
+		{
+			name:        "own identity",
+			args:        map[string]any{"namespace": "test"},
+			wantSuccess: true,
+			wantOutputs: map[string]string{
+				"namespace":        "test",
+				"incomplete":       "false",
+				"nonResourceRules": `[{"verbs":["get"],"nonResourceURLs":["/healthz"]}]`,
+			},
+			wantMessage: []string{"current identity", "pods", "deployments.apps", "[web]", "[/healthz]"},
+		},
+		{
+			name: "impersonated subject",
+			args: map[string]any{
+				"namespace": "test",
+				"as":        "system:serviceaccount:test:agent",
+				"groups":    []any{"system:serviceaccounts"},
+			},
+			wantSuccess: true,
+			wantImpersonate: rest.ImpersonationConfig{
+				UserName: "system:serviceaccount:test:agent",
+				Groups:   []string{"system:serviceaccounts"},
+			},
+			wantMessage: []string{"system:serviceaccount:test:agent"},
+		},
+		{
+			name:           "client error",
+			args:           map[string]any{"namespace": "test"},
+			listErr:        fmt.Errorf("forbidden"),
+			wantErrContain: "failed to list rules",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotImpersonate rest.ImpersonationConfig
+			client := &mockClient{
+				listRulesFn: func(ctx context.Context, namespace string) (*authorizationv1.SubjectRulesReviewStatus, error) {
+					if tt.listErr != nil {
+						return nil, tt.listErr
+					}
+					return rules, nil
+				},
+			}
+			client.impersonateFn = func(impersonate rest.ImpersonationConfig) (ResourceClient, error) {
+				gotImpersonate = impersonate
+				return client, nil
+			}
+
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+			}
+			if tt.clientNil {
+				ext.client = nil
+			}
+
+			result, err := ext.handleListRules(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if gotImpersonate.UserName != tt.wantImpersonate.UserName || strings.Join(gotImpersonate.Groups, ",") != strings.Join(tt.wantImpersonate.Groups, ",") {
+				t.Errorf("impersonate = %+v, want %+v", gotImpersonate, tt.wantImpersonate)
+			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("output %s = %q, want %q", k, got, want)
+				}
+			}
+			for _, want := range tt.wantMessage {
+				if !strings.Contains(result.Message, want) {
+					t.Errorf("expected message to contain %q, got:\n%s", want, result.Message)
+				}
+			}
+		})
+	}
+}
@@ -3,19 +3,33 @@ package extension
 import (
 	"context"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 )
 
 type mockClient struct {
-	createFn            func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error)
-	getFn               func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error)
-	deleteFn            func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error
-	checkAccessFn       func(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error)
-	listContextsFn      func(ctx context.Context) ([]ContextInfo, error)
-	getCurrentContextFn func(ctx context.Context) (string, error)
-	viewConfigFn        func(ctx context.Context, minify bool) (string, error)
+	createFn               func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error)
+	getFn                  func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error)
+	listFn                 func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	updateFn               func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string, subresources ...string) (*unstructured.Unstructured, error)
+	patchFn                func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error)
+	deleteFn               func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error
+	deleteCollectionFn     func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	checkAccessFn          func(ctx context.Context, req AccessRequest) (bool, string, error)
+	createTokenFn          func(ctx context.Context, namespace, serviceAccount string, expirationSeconds int64, audiences []string) (*authenticationv1.TokenRequestStatus, error)
+	listRulesFn            func(ctx context.Context, namespace string) (*authorizationv1.SubjectRulesReviewStatus, error)
+	impersonateFn          func(impersonate rest.ImpersonationConfig) (ResourceClient, error)
+	discoverResourcesFn    func(ctx context.Context) ([]*metav1.APIResourceList, error)
+	discoverGroupVersionFn func(ctx context.Context, groupVersion string) (*metav1.APIResourceList, error)
+	listContextsFn         func(ctx context.Context) ([]ContextInfo, error)
+	getCurrentContextFn    func(ctx context.Context) (string, error)
+	viewConfigFn           func(ctx context.Context, minify bool) (string, error)
 }
 
 func (m *mockClient) Create(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
@@ -29,7 +43,28 @@ func (m *mockClient) Get(ctx context.Context, gvr schema.GroupVersionResource, n
 	if m.getFn != nil {
 		return m.getFn(ctx, gvr, name, namespace)
 	}
-	return nil, nil
+	return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+}
+
+func (m *mockClient) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, gvr, namespace, opts)
+	}
+	return &unstructured.UnstructuredList{}, nil
+}
+
+func (m *mockClient) Update(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string, subresources ...string) (*unstructured.Unstructured, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, gvr, obj, namespace, subresources...)
+	}
+	return obj, nil
+}
+
+func (m *mockClient) Patch(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	if m.patchFn != nil {
+		return m.patchFn(ctx, gvr, name, namespace, patchType, data)
+	}
+	return &unstructured.Unstructured{Object: map[string]any{}}, nil
 }
 
 func (m *mockClient) Delete(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
@@ -39,13 +74,57 @@ func (m *mockClient) Delete(ctx context.Context, gvr schema.GroupVersionResource
 	return nil
 }
 
-func (m *mockClient) CheckAccess(ctx context.Context, user, verb, resource, apiGroup, namespace, resourceName string) (bool, string, error) {
+func (m *mockClient) DeleteCollection(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	if m.deleteCollectionFn != nil {
+		return m.deleteCollectionFn(ctx, gvr, namespace, opts, listOpts)
+	}
+	return nil
+}
+
+func (m *mockClient) CheckAccess(ctx context.Context, req AccessRequest) (bool, string, error) {
 	if m.checkAccessFn != nil {
-		return m.checkAccessFn(ctx, user, verb, resource, apiGroup, namespace, resourceName)
+		return m.checkAccessFn(ctx, req)
 	}
 	return true, "", nil
 }
 
+func (m *mockClient) ListRules(ctx context.Context, namespace string) (*authorizationv1.SubjectRulesReviewStatus, error) {
+	if m.listRulesFn != nil {
+		return m.listRulesFn(ctx, namespace)
+	}
+	return &authorizationv1.SubjectRulesReviewStatus{}, nil
+}
+
+// Impersonate returns the mock itself unless impersonateFn is set, so tests
+// see the requests of impersonated operations on the same functions.
+func (m *mockClient) Impersonate(impersonate rest.ImpersonationConfig) (ResourceClient, error) {
+	if m.impersonateFn != nil {
+		return m.impersonateFn(impersonate)
+	}
+	return m, nil
+}
+
+func (m *mockClient) CreateToken(ctx context.Context, namespace, serviceAccount string, expirationSeconds int64, audiences []string) (*authenticationv1.TokenRequestStatus, error) {
+	if m.createTokenFn != nil {
+		return m.createTokenFn(ctx, namespace, serviceAccount, expirationSeconds, audiences)
+	}
+	return &authenticationv1.TokenRequestStatus{Token: "token"}, nil
+}
+
+func (m *mockClient) DiscoverResources(ctx context.Context) ([]*metav1.APIResourceList, error) {
+	if m.discoverResourcesFn != nil {
+		return m.discoverResourcesFn(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockClient) DiscoverGroupVersion(ctx context.Context, groupVersion string) (*metav1.APIResourceList, error) {
+	if m.discoverGroupVersionFn != nil {
+		return m.discoverGroupVersionFn(ctx, groupVersion)
+	}
+	return &metav1.APIResourceList{GroupVersion: groupVersion}, nil
+}
+
 func (m *mockClient) ListContexts(ctx context.Context) ([]ContextInfo, error) {
 	if m.listContextsFn != nil {
 		return m.listContextsFn(ctx)
@@ -3,8 +3,11 @@ package extension
 import (
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"strings"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -45,6 +48,30 @@ func (e *Extension) handleCreateNamespace(ctx context.Context, req *sdk.Operatio
 		return sdk.Failure(fmt.Errorf("prefix is required")), nil
 	}
 
+	actor, err := e.actorFor(ctx, args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	var profile namespaceProfile
+	profileName, _ := args["profile"].(string)
+	if profileName != "" {
+		p, ok := e.namespaceProfiles[profileName]
+		if !ok {
+			return sdk.Failure(fmt.Errorf("unknown namespace profile %q (available: %s)", profileName, strings.Join(e.profileNames(), ", "))), nil
+		}
+		profile = p
+	}
+
+	inlineLabels, err := stringMapArg(args, "labels")
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	inlineAnnotations, err := stringMapArg(args, "annotations")
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
 	id, err := generateID(8)
 	if err != nil {
 		return sdk.Failure(err), nil
@@ -52,6 +79,16 @@ func (e *Extension) handleCreateNamespace(ctx context.Context, req *sdk.Operatio
 
 	name := fmt.Sprintf("%s-%s", prefix, id)
 
+	// Inline labels and annotations override the profile; the generated and
+	// run labels are applied last so that cleanup can always find the namespace.
+	labels := profile.namespaceLabels()
+	maps.Copy(labels, inlineLabels)
+	labels[generatedLabel] = "true"
+
+	annotations := make(map[string]string)
+	maps.Copy(annotations, profile.Annotations)
+	maps.Copy(annotations, inlineAnnotations)
+
 	obj := &unstructured.Unstructured{
 		Object: map[string]any{
 			"apiVersion": "v1",
@@ -61,12 +98,34 @@ func (e *Extension) handleCreateNamespace(ctx context.Context, req *sdk.Operatio
 			},
 		},
 	}
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	e.applyGCAnnotations(obj)
+
+	e.applyRunLabels(obj)
 
 	e.LogInfo(ctx, "Creating namespace", map[string]any{
-		"name": name,
+		"name":    name,
+		"profile": profileName,
+		"as":      actor.as,
 	})
 
-	result, err := e.client.Create(ctx, namespaceGVR, obj, "")
+	result, err := actor.client.Create(ctx, namespaceGVR, obj, "")
+	if err == nil {
+		e.mu.Lock()
+		e.generatedNamespaces = append(e.generatedNamespaces, result.GetName())
+		if kc := kubeContextName(ctx); kc != "" {
+			if e.namespaceContexts == nil {
+				e.namespaceContexts = make(map[string]string)
+			}
+			e.namespaceContexts[result.GetName()] = kc
+		}
+		e.mu.Unlock()
+		e.persistState(ctx)
+	}
+	if r := actor.forbidden(err, "create namespace "+name); r != nil {
+		return r, nil
+	}
 	if err != nil {
 		e.LogError(ctx, "Failed to create namespace", map[string]any{
 			"name":  name,
@@ -75,20 +134,43 @@ func (e *Extension) handleCreateNamespace(ctx context.Context, req *sdk.Operatio
 		return sdk.Failure(fmt.Errorf("failed to create namespace: %w", err)), nil
 	}
 
-	e.mu.Lock()
-	e.generatedNamespaces = append(e.generatedNamespaces, result.GetName())
-	e.mu.Unlock()
+	outputs := map[string]string{
+		"namespace": result.GetName(),
+	}
+	if profileName != "" {
+		outputs["profile"] = profileName
+	}
+	if profile.ServiceAccount != nil {
+		outputs["serviceAccount"] = profile.ServiceAccount.Name
+	}
+
+	created, err := e.createProfileObjects(ctx, profile, result.GetName())
+	outputs["objects"] = strings.Join(created, ",")
+	if err != nil {
+		e.LogError(ctx, "Failed to set up namespace profile", map[string]any{
+			"name":    result.GetName(),
+			"profile": profileName,
+			"error":   err.Error(),
+		})
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("Created namespace %s, but profile %s could not be applied", result.GetName(), profileName),
+			Error:   err.Error(),
+			Outputs: outputs,
+		}, nil
+	}
 
 	e.LogInfo(ctx, "Namespace created successfully", map[string]any{
-		"name": result.GetName(),
+		"name":    result.GetName(),
+		"profile": profileName,
+		"objects": created,
 	})
 
-	return sdk.SuccessWithOutputs(
-		fmt.Sprintf("Created namespace %s", result.GetName()),
-		map[string]string{
-			"namespace": result.GetName(),
-		},
-	), nil
+	msg := fmt.Sprintf("Created namespace %s", result.GetName())
+	if profileName != "" {
+		msg += fmt.Sprintf(" with profile %s", profileName)
+	}
+	return sdk.SuccessWithOutputs(msg, outputs), nil
 }
 
 func (e *Extension) handleDeleteGeneratedNamespaces(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
@@ -96,29 +178,145 @@ func (e *Extension) handleDeleteGeneratedNamespaces(ctx context.Context, req *sd
 		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
 	}
 
+	args, _ := req.Args.(map[string]any)
+	force, _ := args["force"].(bool)
+	waitForDeletion, _ := args["wait"].(bool)
+
+	timeoutStr, _ := args["timeout"].(string)
+	if timeoutStr == "" {
+		timeoutStr = "60s"
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("invalid timeout: %w", err)), nil
+	}
+
+	concurrency, hasConcurrency, err := intArg(args, "concurrency")
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	if !hasConcurrency {
+		concurrency = defaultTeardownConcurrency
+	}
+	if concurrency < 1 {
+		return sdk.Failure(fmt.Errorf("concurrency must be at least 1")), nil
+	}
+
 	e.mu.Lock()
 	namespaces := make([]string, len(e.generatedNamespaces))
 	copy(namespaces, e.generatedNamespaces)
+	contexts := maps.Clone(e.namespaceContexts)
 	e.generatedNamespaces = nil
 	e.mu.Unlock()
+	e.persistState(ctx)
 
 	if len(namespaces) == 0 {
 		return sdk.Success("No generated namespaces to delete"), nil
 	}
 
 	e.LogInfo(ctx, "Deleting generated namespaces", map[string]any{
-		"count":      len(namespaces),
-		"namespaces": namespaces,
+		"count":       len(namespaces),
+		"namespaces":  namespaces,
+		"force":       force,
+		"wait":        waitForDeletion,
+		"concurrency": concurrency,
 	})
 
+	outcomes := e.teardownNamespaces(ctx, namespaces, contexts, concurrency, force, waitForDeletion, timeout)
+
+	byStatus := make(map[string][]string)
+	statuses := make(map[string]string, len(outcomes))
+	var errs, blocking, failed []string
+	finalized := 0
+	for _, o := range outcomes {
+		byStatus[o.status] = append(byStatus[o.status], o.name)
+		statuses[o.name] = o.status
+		finalized += len(o.termination.finalized)
+		if o.err != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", o.name, o.err))
+			failed = append(failed, o.name)
+		}
+		if len(o.termination.blocking) > 0 {
+			blocking = append(blocking, o.termination.blockingSummary())
+		}
+	}
+
+	// Namespaces that could not be deleted stay tracked so that a later call can retry them.
+	e.mu.Lock()
+	for _, o := range outcomes {
+		if o.err == "" {
+			delete(e.namespaceContexts, o.name)
+		}
+	}
+	if len(failed) > 0 {
+		e.generatedNamespaces = append(failed, e.generatedNamespaces...)
+	}
+	e.mu.Unlock()
+	if len(failed) > 0 {
+		e.persistState(ctx)
+	}
+
+	outcomesJSON, err := json.Marshal(statuses)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to encode outcomes: %w", err)), nil
+	}
+
+	outputs := map[string]string{
+		"outcomes":        string(outcomesJSON),
+		"deleted":         strings.Join(byStatus[namespaceDeleted], ","),
+		"alreadyGone":     strings.Join(byStatus[namespaceAlreadyGone], ","),
+		"terminating":     strings.Join(byStatus[namespaceTerminating], ","),
+		"failed":          strings.Join(byStatus[namespaceFailed], ","),
+		"blockingObjects": strings.Join(blocking, ", "),
+	}
+	if force {
+		outputs["finalizersRemoved"] = fmt.Sprintf("%d", finalized)
+	}
+
+	terminating := byStatus[namespaceTerminating]
+	summary := fmt.Sprintf("%d deleted, %d already gone, %d still terminating, %d failed",
+		len(byStatus[namespaceDeleted]), len(byStatus[namespaceAlreadyGone]), len(terminating), len(failed))
+	if len(blocking) > 0 {
+		summary += fmt.Sprintf(" (blocked by: %s)", strings.Join(blocking, ", "))
+	}
+
+	if len(terminating) > 0 && (waitForDeletion || force) {
+		errs = append(errs, fmt.Sprintf("still terminating: %s", strings.Join(terminating, ", ")))
+	}
+	if len(errs) > 0 {
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("Generated namespace cleanup incomplete: %s", summary),
+			Error:   fmt.Sprintf("failed to clean up namespaces: %s", strings.Join(errs, "; ")),
+			Outputs: outputs,
+		}, nil
+	}
+
+	e.LogInfo(ctx, "Generated namespaces deleted", map[string]any{
+		"outcomes": statuses,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Deleted %d generated namespace(s): %s", len(namespaces), summary),
+		outputs,
+	), nil
+}
+
+// deleteNamespaces deletes the given namespaces, each in its kubeconfig context
+// from contexts, ignoring ones that are already gone. It returns the
+// namespaces that could not be deleted, with one error description each.
+func (e *Extension) deleteNamespaces(ctx context.Context, namespaces []string, contexts map[string]string) ([]string, []string) {
 	propagation := metav1.DeletePropagationForeground
 	deleteOpts := metav1.DeleteOptions{
 		PropagationPolicy: &propagation,
 	}
 
-	var errs []string
+	var failed, errs []string
 	for _, ns := range namespaces {
-		err := e.client.Delete(ctx, namespaceGVR, ns, "", deleteOpts)
+		client, err := e.clientForContext(contexts[ns])
+		if err == nil {
+			err = client.Delete(ctx, namespaceGVR, ns, "", deleteOpts)
+		}
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				e.LogInfo(ctx, "Namespace already deleted (ignored)", map[string]any{
@@ -130,13 +328,10 @@ func (e *Extension) handleDeleteGeneratedNamespaces(ctx context.Context, req *sd
 				"name":  ns,
 				"error": err.Error(),
 			})
+			failed = append(failed, ns)
 			errs = append(errs, fmt.Sprintf("%s: %s", ns, err.Error()))
 		}
 	}
 
-	if len(errs) > 0 {
-		return sdk.Failure(fmt.Errorf("failed to delete namespaces: %s", strings.Join(errs, "; "))), nil
-	}
-
-	return sdk.Success(fmt.Sprintf("Deleted %d generated namespace(s)", len(namespaces))), nil
+	return failed, errs
 }
@@ -120,6 +120,7 @@ func TestHandleDeleteGeneratedNamespaces(t *testing.T) {
 	tests := []struct {
 		name        string
 		tracked     []string
+		args        map[string]any
 		client      *mockClient
 		wantSuccess bool
 		checkTracked func(t *testing.T, ext *Extension)
@@ -168,6 +169,30 @@ func TestHandleDeleteGeneratedNamespaces(t *testing.T) {
 			},
 			wantSuccess: false,
 		},
+		{
+			name:    "stuck namespace reported without force",
+			tracked: []string{"vm-test-stuck"},
+			client: &mockClient{
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					return terminatingNamespace(name, namespaceFinalizersRemaining), nil
+				},
+			},
+			wantSuccess: true,
+		},
+		{
+			name:    "force fails when namespace cannot be finalized",
+			tracked: []string{"vm-test-stuck"},
+			args:    map[string]any{"force": true},
+			client: &mockClient{
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					return terminatingNamespace(name, namespaceFinalizersRemaining), nil
+				},
+				updateFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string, subresources ...string) (*unstructured.Unstructured, error) {
+					return nil, errors.New("permission denied")
+				},
+			},
+			wantSuccess: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,7 +203,11 @@ func TestHandleDeleteGeneratedNamespaces(t *testing.T) {
 				generatedNamespaces: tt.tracked,
 			}
 
-			req := &sdk.OperationRequest{Args: map[string]any{}}
+			args := tt.args
+			if args == nil {
+				args = map[string]any{}
+			}
+			req := &sdk.OperationRequest{Args: args}
 			result, err := ext.handleDeleteGeneratedNamespaces(context.Background(), req)
 
 			if err != nil {
@@ -1,10 +1,84 @@
 package extension
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
 )
 
+// deleteParams is the parameter schema of the delete operation. Its arguments
+// are validated against it before handleDelete runs.
+var deleteParams = jsonschema.Schema{
+	Type:        "object",
+	Description: "Resource reference to delete",
+	Properties: withContextParam(withImpersonationParams(map[string]*jsonschema.Schema{
+		"apiVersion": {
+			Type:        "string",
+			Description: "API version (e.g., v1, apps/v1)",
+		},
+		"kind": {
+			Type:        "string",
+			Description: "Resource kind (e.g., Pod, Namespace)",
+		},
+		"metadata": {
+			Type:        "object",
+			Description: "Resource metadata (name, namespace); name is omitted when deleting by selector",
+		},
+		"labelSelector": {
+			Type:        "string",
+			Description: "Delete all objects matching this label selector (e.g., app=web)",
+		},
+		"fieldSelector": {
+			Type:        "string",
+			Description: "Delete all objects matching this field selector (e.g., status.phase=Failed)",
+		},
+		"ignoreNotFound": {
+			Type:        "boolean",
+			Description: "If true, do not fail when the resource does not exist",
+		},
+		"force": {
+			Type:        "boolean",
+			Description: "If true, remove finalizers that keep the resource (and, for namespaces, its remaining content) from being deleted once terminationTimeout expires; propagation defaults to Background",
+		},
+		"terminationTimeout": {
+			Type:        "string",
+			Description: "With force, how long the resource is given to terminate, or a namespace to report that it is stuck, before finalizers are removed (e.g., 30s, default: 10s)",
+		},
+		"propagationPolicy": {
+			Type:        "string",
+			Description: "How dependents are deleted (default: Foreground)",
+			Enum:        []any{"Foreground", "Background", "Orphan"},
+		},
+		"gracePeriodSeconds": {
+			Type:        "integer",
+			Description: "Seconds before the object is deleted; 0 deletes immediately",
+			Minimum:     jsonschema.Ptr(0.0),
+		},
+		"preconditions": {
+			Type:        "object",
+			Description: "Only delete the object if its UID and/or resourceVersion match",
+			Properties: map[string]*jsonschema.Schema{
+				"uid": {
+					Type:        "string",
+					Description: "Expected UID of the object",
+				},
+				"resourceVersion": {
+					Type:        "string",
+					Description: "Expected resourceVersion of the object",
+				},
+			},
+			AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+		},
+		"dryRun": {
+			Type:        "boolean",
+			Description: "If true, perform a server-side dry run without deleting anything",
+		},
+	})),
+	Required: []string{"apiVersion", "kind"},
+}
+
 // registerOperations adds all available Kubernetes operations to the extension.
 // Each operation is defined with a JSON schema for input validation and a handler function.
 func (e *Extension) registerOperations() {
@@ -14,7 +88,7 @@ func (e *Extension) registerOperations() {
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
 				Description: "Kubernetes resource spec (apiVersion, kind, metadata, spec, etc.)",
-				Properties: map[string]*jsonschema.Schema{
+				Properties: withContextParam(withImpersonationParams(map[string]*jsonschema.Schema{
 					"apiVersion": {
 						Type:        "string",
 						Description: "API version (e.g., v1, apps/v1)",
@@ -31,11 +105,11 @@ func (e *Extension) registerOperations() {
 						Type:        "object",
 						Description: "Resource spec (optional, depends on resource type)",
 					},
-				},
+				})),
 				Required: []string{"apiVersion", "kind", "metadata"},
 			}),
 		),
-		e.handleCreate,
+		e.inContext(e.handleCreate),
 	)
 
 	e.AddOperation(
@@ -44,7 +118,7 @@ func (e *Extension) registerOperations() {
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
 				Description: "Resource reference with condition to wait for",
-				Properties: map[string]*jsonschema.Schema{
+				Properties: withContextParam(withImpersonationParams(map[string]*jsonschema.Schema{
 					"apiVersion": {
 						Type:        "string",
 						Description: "API version (e.g., v1, apps/v1)",
@@ -69,41 +143,333 @@ func (e *Extension) registerOperations() {
 						Type:        "string",
 						Description: "Timeout duration (e.g., 60s, 5m, default: 60s)",
 					},
-				},
+				})),
 				Required: []string{"apiVersion", "kind", "metadata", "condition"},
 			}),
 		),
-		e.handleWait,
+		e.inContext(e.handleWait),
 	)
 
 	e.AddOperation(
 		sdk.NewOperation("delete",
 			sdk.WithDescription("Delete a Kubernetes resource"),
+			sdk.WithParams(deleteParams),
+		),
+		e.inContext(withSchema(deleteParams, e.handleDelete)),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("authCanIMatrix",
+			sdk.WithDescription("Check a list of permissions for one subject and report every mismatch"),
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
-				Description: "Resource reference to delete",
-				Properties: map[string]*jsonschema.Schema{
+				Description: "Permission matrix parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"as": {
+						Type:        "string",
+						Description: "User or service account to check (e.g., alice, system:serviceaccount:ns:sa-name); without as and groups the extension's own identity is checked",
+					},
+					"groups": {
+						Type:        "array",
+						Description: "Groups of the subject (e.g., system:serviceaccounts:ns, OIDC groups)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"uid": {
+						Type:        "string",
+						Description: "UID of the subject (optional)",
+					},
+					"extra": {
+						Type:        "object",
+						Description: "Extra attributes of the subject, each a string or a list of strings (optional)",
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Default namespace for checks that do not set one (optional, empty for cluster-wide)",
+					},
+					"concurrency": {
+						Type:        "integer",
+						Description: "Number of access reviews run in parallel (default: 10)",
+					},
+					"checks": {
+						Type:        "array",
+						Description: "Permissions to check with their expected result",
+						Items: &jsonschema.Schema{
+							Type: "object",
+							Properties: map[string]*jsonschema.Schema{
+								"verb": {
+									Type:        "string",
+									Description: "Action verb (get, list, create, delete, etc.)",
+								},
+								"resource": {
+									Type:        "string",
+									Description: "Resource as a plural, singular, kind or short name, optionally with a group or subresource (pods, deploy, pods/log, deployments.apps)",
+								},
+								"apiGroup": {
+									Type:        "string",
+									Description: "API group (optional, inferred from discovery when unambiguous)",
+								},
+								"namespace": {
+									Type:        "string",
+									Description: "Namespace scope (optional, overrides the default namespace)",
+								},
+								"resourceName": {
+									Type:        "string",
+									Description: "Specific resource name (optional)",
+								},
+								"subresource": {
+									Type:        "string",
+									Description: "Subresource (optional, e.g., log, exec)",
+								},
+								"nonResourceURL": {
+									Type:        "string",
+									Description: "Non-resource URL to check instead of a resource (e.g., /metrics)",
+								},
+								"allowed": {
+									Type:        "boolean",
+									Description: "Expected permission result",
+								},
+							},
+							Required: []string{"verb", "allowed"},
+						},
+					},
+				}),
+				Required: []string{"checks"},
+			}),
+		),
+		e.inContext(e.handleAuthCanIMatrix),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("listRules",
+			sdk.WithDescription("List the rules the extension's identity, or an impersonated subject, is granted in a namespace"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Rules review parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace to evaluate the rules in",
+					},
+					"as": {
+						Type:        "string",
+						Description: "User or service account to impersonate (optional, defaults to the extension's own identity)",
+					},
+					"groups": {
+						Type:        "array",
+						Description: "Groups to impersonate (optional, requires as)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"uid": {
+						Type:        "string",
+						Description: "UID to impersonate (optional, requires as)",
+					},
+					"extra": {
+						Type:        "object",
+						Description: "Extra attributes to impersonate, each a string or a list of strings (optional, requires as)",
+					},
+				}),
+				Required: []string{"namespace"},
+			}),
+		),
+		e.inContext(e.handleListRules),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("assertLeastPrivilege",
+			sdk.WithDescription("Fail if a subject is granted rules in a namespace beyond an allowed set"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Least privilege assertion parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace to evaluate the rules in",
+					},
+					"as": {
+						Type:        "string",
+						Description: "User or service account to impersonate (e.g., system:serviceaccount:ns:sa-name)",
+					},
+					"groups": {
+						Type:        "array",
+						Description: "Groups to impersonate (optional)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"uid": {
+						Type:        "string",
+						Description: "UID to impersonate (optional)",
+					},
+					"extra": {
+						Type:        "object",
+						Description: "Extra attributes to impersonate, each a string or a list of strings (optional)",
+					},
+					"allowed": {
+						Type:        "array",
+						Description: "Rules the subject may be granted; wildcards and escalate, bind and impersonate must be listed explicitly",
+						Items: &jsonschema.Schema{
+							Type: "object",
+							Properties: map[string]*jsonschema.Schema{
+								"verbs": {
+									Type:        "array",
+									Description: "Allowed verbs",
+									Items:       &jsonschema.Schema{Type: "string"},
+								},
+								"apiGroups": {
+									Type:        "array",
+									Description: "API groups of the resources (default: core API)",
+									Items:       &jsonschema.Schema{Type: "string"},
+								},
+								"resources": {
+									Type:        "array",
+									Description: "Allowed resources, including subresources such as pods/log",
+									Items:       &jsonschema.Schema{Type: "string"},
+								},
+								"resourceNames": {
+									Type:        "array",
+									Description: "Restrict the rule to these object names (optional)",
+									Items:       &jsonschema.Schema{Type: "string"},
+								},
+								"nonResourceURLs": {
+									Type:        "array",
+									Description: "Allowed non-resource URLs, instead of resources",
+									Items:       &jsonschema.Schema{Type: "string"},
+								},
+							},
+							Required: []string{"verbs"},
+						},
+					},
+					"includeDefaults": {
+						Type:        "boolean",
+						Description: "Also allow the rules every authenticated user is granted, such as discovery (default: true)",
+					},
+				}),
+				Required: []string{"namespace", "as", "allowed"},
+			}),
+		),
+		e.inContext(e.handleAssertLeastPrivilege),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("serviceAccountKubeconfig",
+			sdk.WithDescription("Write a kubeconfig that authenticates as a ServiceAccount with a bounded token"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "ServiceAccount kubeconfig parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace of the ServiceAccount",
+					},
+					"serviceAccount": {
+						Type:        "string",
+						Description: "Name of the ServiceAccount",
+					},
+					"create": {
+						Type:        "boolean",
+						Description: "Create the ServiceAccount if it does not exist (default: true)",
+					},
+					"expiration": {
+						Type:        "string",
+						Description: "Token lifetime, at least 10m (default: 1h)",
+					},
+					"audiences": {
+						Type:        "array",
+						Description: "Audiences of the token (optional, defaults to the API server)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+				}),
+				Required: []string{"namespace", "serviceAccount"},
+			}),
+		),
+		e.inContext(e.handleServiceAccountKubeconfig),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("whoCan",
+			sdk.WithDescription("List the subjects RBAC allows to perform an action and the bindings that grant it"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Who-can parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"verb": {
+						Type:        "string",
+						Description: "Action verb (get, list, create, delete, etc.)",
+					},
+					"resource": {
+						Type:        "string",
+						Description: "Resource as a plural, singular, kind or short name, optionally with a group or subresource (pods, deploy, pods/log, deployments.apps); required unless nonResourceURL is set",
+					},
+					"apiGroup": {
+						Type:        "string",
+						Description: "API group (optional, inferred from discovery when unambiguous)",
+					},
+					"subresource": {
+						Type:        "string",
+						Description: "Subresource (optional, e.g., log, exec)",
+					},
+					"resourceName": {
+						Type:        "string",
+						Description: "Specific resource name (optional)",
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace to evaluate RoleBindings in (optional, empty for ClusterRoleBindings only)",
+					},
+					"nonResourceURL": {
+						Type:        "string",
+						Description: "Non-resource URL to check instead of a resource (e.g., /metrics)",
+					},
+					"expect": {
+						Type:        "object",
+						Description: "Expected subjects, as User:<name>, Group:<name> or ServiceAccount:<namespace>/<name>",
+						Properties: map[string]*jsonschema.Schema{
+							"subjects": {
+								Type:        "array",
+								Description: "Exact set of allowed subjects",
+								Items:       &jsonschema.Schema{Type: "string"},
+							},
+							"includes": {
+								Type:        "array",
+								Description: "Subjects that must be allowed",
+								Items:       &jsonschema.Schema{Type: "string"},
+							},
+							"excludes": {
+								Type:        "array",
+								Description: "Subjects that must not be allowed",
+								Items:       &jsonschema.Schema{Type: "string"},
+							},
+						},
+					},
+				}),
+				Required: []string{"verb"},
+			}),
+		),
+		e.inContext(e.handleWhoCan),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("describe",
+			sdk.WithDescription("Report a resource, the objects it owns, their container states and related events"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Resource reference to describe",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
 					"apiVersion": {
 						Type:        "string",
 						Description: "API version (e.g., v1, apps/v1)",
 					},
 					"kind": {
 						Type:        "string",
-						Description: "Resource kind (e.g., Pod, Namespace)",
+						Description: "Resource kind (e.g., Pod, Deployment)",
 					},
 					"metadata": {
 						Type:        "object",
 						Description: "Resource metadata (name, namespace)",
 					},
-					"ignoreNotFound": {
-						Type:        "boolean",
-						Description: "If true, do not fail when the resource does not exist",
-					},
-				},
+				}),
 				Required: []string{"apiVersion", "kind", "metadata"},
 			}),
 		),
-		e.handleDelete,
+		e.inContext(e.handleDescribe),
 	)
 
 	e.AddOperation(
@@ -112,18 +478,31 @@ func (e *Extension) registerOperations() {
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
 				Description: "Permission check parameters",
-				Properties: map[string]*jsonschema.Schema{
+				Properties: withContextParam(map[string]*jsonschema.Schema{
 					"verb": {
 						Type:        "string",
 						Description: "Action verb (get, list, create, delete, watch, patch, update, etc.)",
 					},
 					"resource": {
 						Type:        "string",
-						Description: "Resource name (pods, deployments, configmaps, etc.)",
+						Description: "Resource as a plural, singular, kind or short name (pods, Deployment, deploy), optionally as resource/subresource (pods/log) or resource.group (deployments.apps); resolved through discovery and required unless nonResourceURL is set",
 					},
 					"as": {
 						Type:        "string",
-						Description: "User or service account to impersonate (e.g., alice, system:serviceaccount:ns:sa-name)",
+						Description: "User or service account to check (e.g., alice, system:serviceaccount:ns:sa-name); without as and groups the extension's own identity is checked",
+					},
+					"groups": {
+						Type:        "array",
+						Description: "Groups of the subject (e.g., system:serviceaccounts:ns, OIDC groups)",
+						Items:       &jsonschema.Schema{Type: "string"},
+					},
+					"uid": {
+						Type:        "string",
+						Description: "UID of the subject (optional)",
+					},
+					"extra": {
+						Type:        "object",
+						Description: "Extra attributes of the subject, each a string or a list of strings (optional)",
 					},
 					"namespace": {
 						Type:        "string",
@@ -131,12 +510,20 @@ func (e *Extension) registerOperations() {
 					},
 					"apiGroup": {
 						Type:        "string",
-						Description: "API group (optional, empty for core API, e.g., apps, batch, rbac.authorization.k8s.io)",
+						Description: "API group (optional, e.g., apps, batch, rbac.authorization.k8s.io); inferred from discovery when only one group serves the resource",
 					},
 					"resourceName": {
 						Type:        "string",
 						Description: "Specific resource name to check access for (optional)",
 					},
+					"subresource": {
+						Type:        "string",
+						Description: "Subresource to check access for (optional, e.g., log, exec, status)",
+					},
+					"nonResourceURL": {
+						Type:        "string",
+						Description: "Non-resource URL to check instead of a resource (e.g., /metrics, /healthz)",
+					},
 					"expect": {
 						Type:        "object",
 						Description: "Expected result for inline verification",
@@ -147,11 +534,80 @@ func (e *Extension) registerOperations() {
 							},
 						},
 					},
-				},
-				Required: []string{"verb", "resource", "as"},
+				}),
+				Required: []string{"verb"},
 			}),
 		),
-		e.handleAuthCanI,
+		e.inContext(e.handleAuthCanI),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("top",
+			sdk.WithDescription("Query pod or node resource usage from metrics.k8s.io (requires metrics-server)"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Resource usage query parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"resource": {
+						Type:        "string",
+						Description: "Resource type to query (default: pods)",
+						Enum:        []any{"pods", "nodes"},
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace to query pods in (optional, empty for all namespaces, ignored for nodes)",
+					},
+					"labelSelector": {
+						Type:        "string",
+						Description: "Label selector to filter results (e.g., app=web)",
+					},
+					"sortBy": {
+						Type:        "string",
+						Description: "Sort order, highest usage first (default: cpu)",
+						Enum:        []any{"cpu", "memory"},
+					},
+					"limit": {
+						Type:        "integer",
+						Description: "Maximum number of entries to report (default: 5)",
+					},
+				}),
+			}),
+		),
+		e.inContext(e.handleTop),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("scenario",
+			sdk.WithDescription("Instantiate a named broken-workload scenario and wait until its failure is visible"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Scenario parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"scenario": {
+						Type:        "string",
+						Description: "Scenario name from the built-in catalog",
+						Enum: []any{
+							"crash-loop-backoff",
+							"failing-readiness-probe",
+							"image-pull-backoff",
+							"missing-configmap",
+							"unschedulable-resources",
+							"wrong-service-selector",
+						},
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace to create the scenario objects in",
+					},
+					"timeout": {
+						Type:        "string",
+						Description: "How long to wait for the failure to become visible (e.g., 60s, 5m, default: 120s)",
+					},
+				}),
+				Required: []string{"scenario", "namespace"},
+			}),
+		),
+		e.inContext(e.handleScenario),
 	)
 
 	e.AddOperation(
@@ -182,15 +638,178 @@ func (e *Extension) registerOperations() {
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
 				Description: "Configuration view options",
-				Properties: map[string]*jsonschema.Schema{
+				Properties: withContextParam(map[string]*jsonschema.Schema{
 					"minify": {
 						Type:        "boolean",
 						Description: "If true, only show current context (default: false)",
 					},
+				}),
+			}),
+		),
+		e.inContext(e.handleViewConfig),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("useContext",
+			sdk.WithDescription("Switch the current context of the kubeconfig; the original file is restored by restoreKubeconfig"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Context to switch to",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Name of the kubeconfig context",
+					},
+				},
+				Required: []string{"name"},
+			}),
+		),
+		e.handleUseContext,
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("setContextNamespace",
+			sdk.WithDescription("Set the default namespace of a kubeconfig context; the original file is restored by restoreKubeconfig"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Context and namespace",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Name of the kubeconfig context (default: the current context)",
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Default namespace of the context; empty clears it",
+					},
 				},
+				Required: []string{"namespace"},
 			}),
 		),
-		e.handleViewConfig,
+		e.handleSetContextNamespace,
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("addContext",
+			sdk.WithDescription("Add a context for an existing cluster and user to the kubeconfig; the original file is restored by restoreKubeconfig"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Context to add",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Name of the new context",
+					},
+					"cluster": {
+						Type:        "string",
+						Description: "Name of a cluster in the kubeconfig",
+					},
+					"user": {
+						Type:        "string",
+						Description: "Name of a user in the kubeconfig",
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Default namespace of the context",
+					},
+					"overwrite": {
+						Type:        "boolean",
+						Description: "Replace a context with the same name (default: false)",
+					},
+				},
+				Required: []string{"name", "cluster"},
+			}),
+		),
+		e.handleAddContext,
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("deleteContext",
+			sdk.WithDescription("Delete a context from the kubeconfig; the original file is restored by restoreKubeconfig"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Context to delete",
+				Properties: map[string]*jsonschema.Schema{
+					"name": {
+						Type:        "string",
+						Description: "Name of the kubeconfig context",
+					},
+				},
+				Required: []string{"name"},
+			}),
+		),
+		e.handleDeleteContext,
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("restoreKubeconfig",
+			sdk.WithDescription("Restore the kubeconfig files modified by useContext, setContextNamespace, addContext and deleteContext"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "No parameters required",
+			}),
+		),
+		e.handleRestoreKubeconfig,
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("sandboxKubeconfig",
+			sdk.WithDescription("Copy the kubeconfig to a temporary file for an MCP server, so changes the agent makes do not reach the real kubeconfig"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Sandbox kubeconfig options",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"minify": {
+						Type:        "boolean",
+						Description: "If true, only copy the selected context and its cluster and user (default: false)",
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Default namespace of the copy's current context",
+					},
+					"generatedNamespace": {
+						Type:        "boolean",
+						Description: "Default to the namespace most recently created by createNamespace in the selected context; set to false to keep the context's namespace (default: true unless namespace is set)",
+					},
+				}),
+			}),
+		),
+		e.inContext(e.handleSandboxKubeconfig),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("diffSandboxKubeconfig",
+			sdk.WithDescription("Report how a sandbox kubeconfig changed since sandboxKubeconfig wrote it"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Sandbox kubeconfig to compare",
+				Properties: map[string]*jsonschema.Schema{
+					"path": {
+						Type:        "string",
+						Description: "Path returned by sandboxKubeconfig (default: the most recent sandbox)",
+					},
+					"expect": {
+						Type:        "object",
+						Description: "Fail unless the sandbox matches",
+						Properties: map[string]*jsonschema.Schema{
+							"changed": {
+								Type:        "boolean",
+								Description: "Whether the kubeconfig is expected to have changed",
+							},
+							"currentContext": {
+								Type:        "string",
+								Description: "Expected current context",
+							},
+							"namespace": {
+								Type:        "string",
+								Description: "Expected default namespace of the current context",
+							},
+						},
+					},
+				},
+			}),
+		),
+		e.handleDiffSandboxKubeconfig,
 	)
 
 	e.AddOperation(
@@ -199,16 +818,28 @@ func (e *Extension) registerOperations() {
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
 				Description: "Namespace creation parameters",
-				Properties: map[string]*jsonschema.Schema{
+				Properties: withContextParam(withImpersonationParams(map[string]*jsonschema.Schema{
 					"prefix": {
 						Type:        "string",
 						Description: "Prefix for the namespace name (e.g., vm-test produces vm-test-a1b2c3)",
 					},
-				},
+					"profile": {
+						Type:        "string",
+						Description: "Name of a namespace profile from the extension configuration",
+					},
+					"labels": {
+						Type:        "object",
+						Description: "Labels to set on the namespace, overriding the profile",
+					},
+					"annotations": {
+						Type:        "object",
+						Description: "Annotations to set on the namespace, overriding the profile",
+					},
+				})),
 				Required: []string{"prefix"},
 			}),
 		),
-		e.handleCreateNamespace,
+		e.inContext(e.handleCreateNamespace),
 	)
 
 	e.AddOperation(
@@ -216,10 +847,130 @@ func (e *Extension) registerOperations() {
 			sdk.WithDescription("Delete all namespaces previously created by createNamespace"),
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
-				Description: "No parameters required",
+				Description: "Generated namespace cleanup parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"force": {
+						Type:        "boolean",
+						Description: "If true, remove finalizers from objects left in terminating namespaces and from the namespaces themselves",
+					},
+					"wait": {
+						Type:        "boolean",
+						Description: "If true, wait until the namespaces are gone (default: false)",
+					},
+					"timeout": {
+						Type:        "string",
+						Description: "How long to wait for each namespace (e.g., 60s, 5m, default: 60s)",
+					},
+					"concurrency": {
+						Type:        "integer",
+						Description: "Number of namespaces deleted in parallel (default: 5)",
+					},
+				}),
+			}),
+		),
+		e.inContext(e.handleDeleteGeneratedNamespaces),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("resetNamespace",
+			sdk.WithDescription("Delete all objects in a namespace, except excluded ones, without deleting the namespace"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Namespace reset parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace to reset",
+					},
+					"exclude": {
+						Type:        "array",
+						Description: "Objects to keep in addition to the default ServiceAccount and kube-root-ca.crt ConfigMap",
+						Items: &jsonschema.Schema{
+							Type: "object",
+							Properties: map[string]*jsonschema.Schema{
+								"kind": {
+									Type:        "string",
+									Description: "Kind of the objects to keep (e.g., Secret)",
+								},
+								"name": {
+									Type:        "string",
+									Description: "Name of the object to keep; all objects of the kind are kept when omitted",
+								},
+							},
+							Required: []string{"kind"},
+						},
+					},
+					"keepSelector": {
+						Type:        "string",
+						Description: "Label selector of objects to keep (e.g., keep=true)",
+					},
+					"wait": {
+						Type:        "boolean",
+						Description: "If false, do not wait until the deleted objects are gone (default: true)",
+					},
+					"timeout": {
+						Type:        "string",
+						Description: "How long to wait for deletion (e.g., 60s, 5m, default: 60s)",
+					},
+				}),
+				Required: []string{"namespace"},
 			}),
 		),
-		e.handleDeleteGeneratedNamespaces,
+		e.inContext(e.handleResetNamespace),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("deleteTracked",
+			sdk.WithDescription("Delete all resources created by this extension in reverse creation order"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Tracked resource cleanup parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"wait": {
+						Type:        "boolean",
+						Description: "If true, wait until the deleted resources are gone (default: false)",
+					},
+					"timeout": {
+						Type:        "string",
+						Description: "How long to wait for deletion (e.g., 60s, 5m, default: 60s)",
+					},
+				}),
+			}),
+		),
+		e.inContext(e.handleDeleteTracked),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("cleanupByLabel",
+			sdk.WithDescription("Delete all resources carrying the run label, across all listable resource types"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Label sweep parameters",
+				Properties: withContextParam(map[string]*jsonschema.Schema{
+					"runId": {
+						Type:        "string",
+						Description: "Run ID to sweep (optional, defaults to the current run)",
+					},
+					"task": {
+						Type:        "string",
+						Description: "Only sweep resources with this task label (optional)",
+					},
+				}),
+			}),
+		),
+		e.inContext(e.handleCleanupByLabel),
+	)
+
+	e.AddOperation(
+		sdk.NewOperation("gcStale",
+			sdk.WithDescription("Delete expired generated namespaces and state file entries left behind by earlier runs"),
+			sdk.WithParams(jsonschema.Schema{
+				Type:        "object",
+				Description: "Garbage collection parameters",
+				Properties:  withContextParam(map[string]*jsonschema.Schema{}),
+			}),
+		),
+		e.inContext(e.handleGCStale),
 	)
 
 	// Helm operations
@@ -229,7 +980,7 @@ func (e *Extension) registerOperations() {
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
 				Description: "Helm chart installation parameters",
-				Properties: map[string]*jsonschema.Schema{
+				Properties: withContextParam(map[string]*jsonschema.Schema{
 					"chart": {
 						Type:        "string",
 						Description: "Chart reference (e.g., bitnami/nginx, oci://registry-1.docker.io/bitnamicharts/nginx)",
@@ -246,11 +997,11 @@ func (e *Extension) registerOperations() {
 						Type:        "object",
 						Description: "Helm values to set (optional)",
 					},
-				},
+				}),
 				Required: []string{"chart"},
 			}),
 		),
-		e.handleHelmInstall,
+		e.inContext(e.handleHelmInstall),
 	)
 
 	e.AddOperation(
@@ -259,7 +1010,7 @@ func (e *Extension) registerOperations() {
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
 				Description: "Helm list parameters",
-				Properties: map[string]*jsonschema.Schema{
+				Properties: withContextParam(map[string]*jsonschema.Schema{
 					"namespace": {
 						Type:        "string",
 						Description: "Namespace to list releases from (optional)",
@@ -268,10 +1019,10 @@ func (e *Extension) registerOperations() {
 						Type:        "boolean",
 						Description: "List releases from all namespaces (default: false)",
 					},
-				},
+				}),
 			}),
 		),
-		e.handleHelmList,
+		e.inContext(e.handleHelmList),
 	)
 
 	e.AddOperation(
@@ -280,7 +1031,7 @@ func (e *Extension) registerOperations() {
 			sdk.WithParams(jsonschema.Schema{
 				Type:        "object",
 				Description: "Helm uninstall parameters",
-				Properties: map[string]*jsonschema.Schema{
+				Properties: withContextParam(map[string]*jsonschema.Schema{
 					"name": {
 						Type:        "string",
 						Description: "Release name to uninstall",
@@ -289,10 +1040,59 @@ func (e *Extension) registerOperations() {
 						Type:        "string",
 						Description: "Release namespace (optional)",
 					},
-				},
+				}),
 				Required: []string{"name"},
 			}),
 		),
-		e.handleHelmUninstall,
+		e.inContext(e.handleHelmUninstall),
 	)
 }
+
+// withSchema wraps a handler so that its arguments are validated against the
+// operation's parameter schema before the handler runs.
+func withSchema(params jsonschema.Schema, handler sdk.OperationHandler) sdk.OperationHandler {
+	resolved, err := params.Resolve(nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid operation schema: %v", err))
+	}
+
+	return func(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+		if err := resolved.Validate(req.Args); err != nil {
+			return sdk.Failure(fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		return handler(ctx, req)
+	}
+}
+
+// withImpersonationParams adds the parameters of operations that can be
+// performed as another subject to props.
+func withImpersonationParams(props map[string]*jsonschema.Schema) map[string]*jsonschema.Schema {
+	props["as"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "User or service account to perform the operation as (optional, e.g., alice, system:serviceaccount:ns:sa-name)",
+	}
+	props["asGroups"] = &jsonschema.Schema{
+		Type:        "array",
+		Description: "Groups to impersonate (optional, requires as)",
+		Items:       &jsonschema.Schema{Type: "string"},
+	}
+	props["asUID"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "UID to impersonate (optional, requires as)",
+	}
+	props["expectForbidden"] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Succeed only if the request is forbidden (default: false)",
+	}
+	return props
+}
+
+// withContextParam adds the parameter selecting the kubeconfig context an
+// operation runs against to props.
+func withContextParam(props map[string]*jsonschema.Schema) map[string]*jsonschema.Schema {
+	props["context"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Kubeconfig context to run the operation against (optional, defaults to the configured context)",
+	}
+	return props
+}
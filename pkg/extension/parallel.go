@@ -0,0 +1,26 @@
+package extension
+
+import "sync"
+
+// forEachParallel calls fn for every index in [0, n) with at most concurrency
+// calls in flight, and returns once all calls are done.
+func forEachParallel(n, concurrency int, fn func(i int)) {
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,200 @@
+package extension
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	serviceAccountGVR = schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+	resourceQuotaGVR  = schema.GroupVersionResource{Version: "v1", Resource: "resourcequotas"}
+	limitRangeGVR     = schema.GroupVersionResource{Version: "v1", Resource: "limitranges"}
+	roleBindingGVR    = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"}
+)
+
+const (
+	// profileQuotaName and profileLimitRangeName name the objects created for a profile's quota and limits.
+	profileQuotaName      = "mcpchecker-quota"
+	profileLimitRangeName = "mcpchecker-limits"
+
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+)
+
+// podSecurityLevels are the Pod Security Admission levels.
+var podSecurityLevels = []string{"privileged", "baseline", "restricted"}
+
+// namespaceProfile describes how createNamespace sets up a namespace. Profiles
+// are defined under namespaceProfiles in the extension configuration.
+type namespaceProfile struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	PodSecurity *podSecurity      `json:"podSecurity,omitempty"`
+	// ResourceQuota and LimitRange hold the spec of the object created in the namespace.
+	ResourceQuota  map[string]any  `json:"resourceQuota,omitempty"`
+	LimitRange     map[string]any  `json:"limitRange,omitempty"`
+	ServiceAccount *profileAccount `json:"serviceAccount,omitempty"`
+}
+
+// podSecurity holds the Pod Security Admission levels applied as namespace labels.
+type podSecurity struct {
+	Enforce string `json:"enforce,omitempty"`
+	Audit   string `json:"audit,omitempty"`
+	Warn    string `json:"warn,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// profileAccount is a ServiceAccount created in the namespace, optionally bound
+// to a ClusterRole through a RoleBinding in the same namespace.
+type profileAccount struct {
+	Name        string `json:"name"`
+	ClusterRole string `json:"clusterRole,omitempty"`
+}
+
+// parseNamespaceProfiles decodes and validates the namespaceProfiles configuration.
+func parseNamespaceProfiles(raw any) (map[string]namespaceProfile, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespaceProfiles: %w", err)
+	}
+
+	var profiles map[string]namespaceProfile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&profiles); err != nil {
+		return nil, fmt.Errorf("invalid namespaceProfiles: %w", err)
+	}
+
+	for name, p := range profiles {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid namespace profile %q: %w", name, err)
+		}
+	}
+
+	return profiles, nil
+}
+
+func (p namespaceProfile) validate() error {
+	if p.PodSecurity != nil {
+		for mode, level := range map[string]string{"enforce": p.PodSecurity.Enforce, "audit": p.PodSecurity.Audit, "warn": p.PodSecurity.Warn} {
+			if level != "" && !slices.Contains(podSecurityLevels, level) {
+				return fmt.Errorf("podSecurity.%s must be one of %s, got %q", mode, strings.Join(podSecurityLevels, ", "), level)
+			}
+		}
+	}
+	if p.ServiceAccount != nil && p.ServiceAccount.Name == "" {
+		return fmt.Errorf("serviceAccount.name is required")
+	}
+	return nil
+}
+
+// namespaceLabels returns the profile labels, including the Pod Security Admission labels.
+func (p namespaceProfile) namespaceLabels() map[string]string {
+	labels := make(map[string]string, len(p.Labels))
+	for k, v := range p.Labels {
+		labels[k] = v
+	}
+	if ps := p.PodSecurity; ps != nil {
+		for mode, level := range map[string]string{"enforce": ps.Enforce, "audit": ps.Audit, "warn": ps.Warn} {
+			if level == "" {
+				continue
+			}
+			labels[podSecurityLabelPrefix+mode] = level
+			if ps.Version != "" {
+				labels[podSecurityLabelPrefix+mode+"-version"] = ps.Version
+			}
+		}
+	}
+	return labels
+}
+
+// profileObject is an object created in a namespace for its profile.
+type profileObject struct {
+	gvr schema.GroupVersionResource
+	obj *unstructured.Unstructured
+}
+
+// objects returns the objects to create in the namespace, in creation order.
+func (p namespaceProfile) objects() []profileObject {
+	var objects []profileObject
+
+	if sa := p.ServiceAccount; sa != nil {
+		objects = append(objects, profileObject{serviceAccountGVR, newObject("v1", "ServiceAccount", sa.Name, nil)})
+		if sa.ClusterRole != "" {
+			binding := newObject("rbac.authorization.k8s.io/v1", "RoleBinding", sa.Name+"-"+sa.ClusterRole, nil)
+			binding.Object["roleRef"] = map[string]any{
+				"apiGroup": "rbac.authorization.k8s.io",
+				"kind":     "ClusterRole",
+				"name":     sa.ClusterRole,
+			}
+			binding.Object["subjects"] = []any{
+				map[string]any{"kind": "ServiceAccount", "name": sa.Name},
+			}
+			objects = append(objects, profileObject{roleBindingGVR, binding})
+		}
+	}
+	if p.ResourceQuota != nil {
+		objects = append(objects, profileObject{resourceQuotaGVR, newObject("v1", "ResourceQuota", profileQuotaName, p.ResourceQuota)})
+	}
+	if p.LimitRange != nil {
+		objects = append(objects, profileObject{limitRangeGVR, newObject("v1", "LimitRange", profileLimitRangeName, p.LimitRange)})
+	}
+
+	return objects
+}
+
+func newObject(apiVersion, kind, name string, spec map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]any{
+				"name": name,
+			},
+		},
+	}
+	if spec != nil {
+		obj.Object["spec"] = runtime.DeepCopyJSON(spec)
+	}
+	return obj
+}
+
+// profileNames returns the configured profile names, sorted.
+func (e *Extension) profileNames() []string {
+	names := make([]string, 0, len(e.namespaceProfiles))
+	for name := range e.namespaceProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// createProfileObjects creates the objects of a profile in the given namespace
+// and tracks them for cleanup. It returns the names of the created objects.
+func (e *Extension) createProfileObjects(ctx context.Context, profile namespaceProfile, namespace string) ([]string, error) {
+	var created []string
+	for _, po := range profile.objects() {
+		po.obj.SetNamespace(namespace)
+		e.applyRunLabels(po.obj)
+
+		result, err := e.kube(ctx).Create(ctx, po.gvr, po.obj, namespace)
+		if err != nil {
+			return created, fmt.Errorf("failed to create %s %s: %w", po.obj.GetKind(), po.obj.GetName(), err)
+		}
+		e.trackResource(ctx, po.gvr, result)
+		created = append(created, fmt.Sprintf("%s/%s", po.obj.GetKind(), po.obj.GetName()))
+	}
+	return created, nil
+}
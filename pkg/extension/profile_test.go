@@ -0,0 +1,215 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseNamespaceProfiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		raw            any
+		wantProfiles   []string
+		wantErrContain string
+	}{
+		{
+			name: "no profiles configured",
+		},
+		{
+			name: "valid profiles",
+			raw: map[string]any{
+				"restricted": map[string]any{
+					"podSecurity":    map[string]any{"enforce": "restricted", "warn": "restricted"},
+					"resourceQuota":  map[string]any{"hard": map[string]any{"pods": "10"}},
+					"serviceAccount": map[string]any{"name": "agent", "clusterRole": "edit"},
+				},
+				"labelled": map[string]any{
+					"labels": map[string]any{"team": "qa"},
+				},
+			},
+			wantProfiles: []string{"labelled", "restricted"},
+		},
+		{
+			name: "unknown field",
+			raw: map[string]any{
+				"typo": map[string]any{"lables": map[string]any{"team": "qa"}},
+			},
+			wantErrContain: "unknown field",
+		},
+		{
+			name: "invalid pod security level",
+			raw: map[string]any{
+				"strict": map[string]any{"podSecurity": map[string]any{"enforce": "strict"}},
+			},
+			wantErrContain: "podSecurity.enforce",
+		},
+		{
+			name: "service account without name",
+			raw: map[string]any{
+				"sa": map[string]any{"serviceAccount": map[string]any{"clusterRole": "view"}},
+			},
+			wantErrContain: "serviceAccount.name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, err := parseNamespaceProfiles(tt.raw)
+			if tt.wantErrContain != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrContain) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErrContain, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ext := &Extension{namespaceProfiles: profiles}
+			if got := ext.profileNames(); !slices.Equal(got, tt.wantProfiles) {
+				t.Errorf("profiles = %v, want %v", got, tt.wantProfiles)
+			}
+		})
+	}
+}
+
+func TestHandleCreateNamespaceWithProfile(t *testing.T) {
+	profiles, err := parseNamespaceProfiles(map[string]any{
+		"restricted": map[string]any{
+			"labels":         map[string]any{"team": "qa", "tier": "profile"},
+			"annotations":    map[string]any{"owner": "profile"},
+			"podSecurity":    map[string]any{"enforce": "restricted", "version": "latest"},
+			"resourceQuota":  map[string]any{"hard": map[string]any{"pods": "10"}},
+			"limitRange":     map[string]any{"limits": []any{map[string]any{"type": "Container"}}},
+			"serviceAccount": map[string]any{"name": "agent", "clusterRole": "edit"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to parse profiles: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		args           map[string]any
+		createErr      map[string]error
+		wantSuccess    bool
+		wantErrContain string
+		wantCreated    []string
+		wantLabels     map[string]string
+		wantAnnotation map[string]string
+		wantTracked    int
+	}{
+		{
+			name: "profile objects are created and tracked",
+			args: map[string]any{
+				"prefix":      "test",
+				"profile":     "restricted",
+				"labels":      map[string]any{"tier": "inline"},
+				"annotations": map[string]any{"note": "inline"},
+			},
+			wantSuccess: true,
+			wantCreated: []string{"namespaces", "serviceaccounts", "rolebindings", "resourcequotas", "limitranges"},
+			wantLabels: map[string]string{
+				"team":                               "qa",
+				"tier":                               "inline",
+				"pod-security.kubernetes.io/enforce": "restricted",
+				"pod-security.kubernetes.io/enforce-version": "latest",
+				generatedLabel: "true",
+			},
+			wantAnnotation: map[string]string{"owner": "profile", "note": "inline"},
+			wantTracked:    4,
+		},
+		{
+			name:        "inline labels without profile",
+			args:        map[string]any{"prefix": "test", "labels": map[string]any{"team": "qa"}},
+			wantSuccess: true,
+			wantCreated: []string{"namespaces"},
+			wantLabels:  map[string]string{"team": "qa", generatedLabel: "true"},
+		},
+		{
+			name:           "unknown profile",
+			args:           map[string]any{"prefix": "test", "profile": "missing"},
+			wantSuccess:    false,
+			wantErrContain: "available: restricted",
+		},
+		{
+			name:           "non-string label value",
+			args:           map[string]any{"prefix": "test", "labels": map[string]any{"count": float64(1)}},
+			wantSuccess:    false,
+			wantErrContain: "labels.count must be a string",
+		},
+		{
+			name:           "failing profile object keeps earlier objects tracked",
+			args:           map[string]any{"prefix": "test", "profile": "restricted"},
+			createErr:      map[string]error{"resourcequotas": errors.New("quota exceeded")},
+			wantSuccess:    false,
+			wantErrContain: "failed to create ResourceQuota mcpchecker-quota",
+			wantCreated:    []string{"namespaces", "serviceaccounts", "rolebindings"},
+			wantTracked:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []string
+			var namespace *unstructured.Unstructured
+			client := &mockClient{
+				createFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, ns string) (*unstructured.Unstructured, error) {
+					if err := tt.createErr[gvr.Resource]; err != nil {
+						return nil, err
+					}
+					if gvr == namespaceGVR {
+						namespace = obj
+					} else if ns == "" || obj.GetNamespace() != ns {
+						t.Errorf("expected %s to be created in the new namespace, got %q", gvr.Resource, ns)
+					}
+					created = append(created, gvr.Resource)
+					return obj.DeepCopy(), nil
+				},
+			}
+
+			ext := &Extension{
+				Extension:         sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:            client,
+				namespaceProfiles: profiles,
+			}
+
+			result, err := ext.handleCreateNamespace(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if !slices.Equal(created, tt.wantCreated) {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+			if len(ext.trackedResources) != tt.wantTracked {
+				t.Errorf("expected %d tracked resource(s), got %d", tt.wantTracked, len(ext.trackedResources))
+			}
+
+			if tt.wantLabels != nil {
+				labels := namespace.GetLabels()
+				for k, v := range tt.wantLabels {
+					if labels[k] != v {
+						t.Errorf("label %s = %q, want %q", k, labels[k], v)
+					}
+				}
+			}
+			for k, v := range tt.wantAnnotation {
+				if got := namespace.GetAnnotations()[k]; got != v {
+					t.Errorf("annotation %s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
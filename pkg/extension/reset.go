@@ -0,0 +1,202 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// excludeRule keeps objects of a kind, or a single named object, during resetNamespace.
+type excludeRule struct {
+	kind string
+	name string
+}
+
+func (r excludeRule) matches(kind, name string) bool {
+	return strings.EqualFold(r.kind, kind) && (r.name == "" || r.name == name)
+}
+
+// defaultResetExcludes are the objects Kubernetes creates in every namespace.
+var defaultResetExcludes = []excludeRule{
+	{kind: "ServiceAccount", name: "default"},
+	{kind: "ConfigMap", name: "kube-root-ca.crt"},
+}
+
+// parseExcludeRules reads the exclude argument: a list of {kind, name} objects
+// where name is optional.
+func parseExcludeRules(args map[string]any) ([]excludeRule, error) {
+	raw, ok := args["exclude"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("exclude must be a list")
+	}
+
+	rules := make([]excludeRule, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("exclude[%d] must be an object", i)
+		}
+		kind, _ := m["kind"].(string)
+		if kind == "" {
+			return nil, fmt.Errorf("exclude[%d].kind is required", i)
+		}
+		name, _ := m["name"].(string)
+		rules = append(rules, excludeRule{kind: kind, name: name})
+	}
+	return rules, nil
+}
+
+// handleResetNamespace deletes every object in a namespace, except excluded
+// ones, without deleting the namespace itself.
+func (e *Extension) handleResetNamespace(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	namespace, _ := args["namespace"].(string)
+	if namespace == "" {
+		return sdk.Failure(fmt.Errorf("namespace is required")), nil
+	}
+
+	rules, err := parseExcludeRules(args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	rules = append(rules, defaultResetExcludes...)
+
+	keepSelector := labels.Nothing()
+	if selector, _ := args["keepSelector"].(string); selector != "" {
+		keepSelector, err = labels.Parse(selector)
+		if err != nil {
+			return sdk.Failure(fmt.Errorf("invalid keepSelector: %w", err)), nil
+		}
+	}
+
+	waitForDeletion := true
+	if w, ok := args["wait"].(bool); ok {
+		waitForDeletion = w
+	}
+
+	timeoutStr, _ := args["timeout"].(string)
+	if timeoutStr == "" {
+		timeoutStr = "60s"
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("invalid timeout: %w", err)), nil
+	}
+
+	resources, err := e.discoverResources(ctx, "list", "delete")
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	e.LogInfo(ctx, "Resetting namespace", map[string]any{
+		"namespace": namespace,
+		"types":     len(resources),
+	})
+
+	var (
+		targets []trackedResource
+		kept    int
+		errs    []string
+	)
+	for _, r := range resources {
+		if !r.namespaced {
+			continue
+		}
+		list, err := e.kube(ctx).List(ctx, r.gvr, namespace, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to list %s: %s", r.gvr.Resource, err.Error()))
+			continue
+		}
+		for _, item := range list.Items {
+			if keepSelector.Matches(labels.Set(item.GetLabels())) || matchesAnyRule(rules, r.kind, item.GetName()) {
+				kept++
+				continue
+			}
+			// Objects with a controller are removed by the garbage collector
+			// together with their owner, so deleting them would only race the controller.
+			if metav1.GetControllerOf(&item) != nil {
+				continue
+			}
+			targets = append(targets, trackedResource{
+				gvr:       r.gvr,
+				kind:      r.kind,
+				namespace: namespace,
+				name:      item.GetName(),
+				uid:       item.GetUID(),
+				context:   kubeContextName(ctx),
+			})
+		}
+	}
+
+	deleted, skipped, leftovers, deleteErrs := e.deleteTrackedResources(ctx, targets, waitForDeletion, timeout)
+	errs = append(errs, deleteErrs...)
+
+	leftoverNames := make([]string, len(leftovers))
+	for i, r := range leftovers {
+		leftoverNames[i] = r.String()
+	}
+
+	outputs := map[string]string{
+		"namespace": namespace,
+		"deleted":   fmt.Sprintf("%d", deleted),
+		"kept":      fmt.Sprintf("%d", kept),
+		"leftovers": strings.Join(leftoverNames, ","),
+	}
+
+	if len(errs) > 0 || len(leftovers) > 0 {
+		e.LogError(ctx, "Namespace reset incomplete", map[string]any{
+			"namespace": namespace,
+			"errors":    errs,
+			"leftovers": leftoverNames,
+		})
+		errMsg := fmt.Sprintf("%d object(s) still present", len(leftovers))
+		if len(errs) > 0 {
+			errMsg = strings.Join(errs, "; ")
+		}
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("Reset of namespace %s incomplete: deleted %d object(s), %d left over", namespace, deleted, len(leftovers)),
+			Error:   fmt.Sprintf("failed to reset namespace: %s", errMsg),
+			Outputs: outputs,
+		}, nil
+	}
+
+	e.LogInfo(ctx, "Namespace reset", map[string]any{
+		"namespace": namespace,
+		"deleted":   deleted,
+		"skipped":   skipped,
+		"kept":      kept,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Reset namespace %s: deleted %d object(s), kept %d", namespace, deleted, kept),
+		outputs,
+	), nil
+}
+
+func matchesAnyRule(rules []excludeRule, kind, name string) bool {
+	for _, rule := range rules {
+		if rule.matches(kind, name) {
+			return true
+		}
+	}
+	return false
+}
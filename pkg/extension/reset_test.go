@@ -0,0 +1,148 @@
+package extension
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestHandleResetNamespace(t *testing.T) {
+	discovered := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace", Verbs: []string{"list", "delete"}},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "serviceaccounts", Kind: "ServiceAccount", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: []string{"create"}},
+			},
+		},
+	}
+
+	object := func(name string, labels map[string]string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]any{}}
+		obj.SetName(name)
+		obj.SetUID(types.UID("uid-" + name))
+		obj.SetLabels(labels)
+		return obj
+	}
+	isController := true
+	owned := object("web-abc", nil)
+	owned.SetOwnerReferences([]metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web", Controller: &isController}})
+
+	contents := map[string][]unstructured.Unstructured{
+		"configmaps":      {object("kube-root-ca.crt", nil), object("settings", nil), object("pinned", map[string]string{"keep": "true"})},
+		"serviceaccounts": {object("default", nil), object("agent", nil)},
+		"pods":            {object("standalone", nil), owned},
+	}
+
+	tests := []struct {
+		name           string
+		args           any
+		clientNil      bool
+		wantSuccess    bool
+		wantErrContain string
+		wantDeleted    []string
+		wantOutputs    map[string]string
+	}{
+		{
+			name:           "client not initialized",
+			args:           map[string]any{"namespace": "test"},
+			clientNil:      true,
+			wantErrContain: "kubernetes client not initialized",
+		},
+		{
+			name:           "missing namespace",
+			args:           map[string]any{},
+			wantErrContain: "namespace is required",
+		},
+		{
+			name:           "invalid exclude",
+			args:           map[string]any{"namespace": "test", "exclude": []any{map[string]any{"name": "x"}}},
+			wantErrContain: "exclude[0].kind is required",
+		},
+		{
+			name:           "invalid keep selector",
+			args:           map[string]any{"namespace": "test", "keepSelector": "a in"},
+			wantErrContain: "invalid keepSelector",
+		},
+		{
+			name:        "default excludes",
+			args:        map[string]any{"namespace": "test", "wait": false},
+			wantSuccess: true,
+			wantDeleted: []string{"pods/standalone", "serviceaccounts/agent", "configmaps/pinned", "configmaps/settings"},
+			wantOutputs: map[string]string{"deleted": "4", "kept": "2"},
+		},
+		{
+			name: "exclude list and keep selector",
+			args: map[string]any{
+				"namespace":    "test",
+				"wait":         false,
+				"exclude":      []any{map[string]any{"kind": "serviceaccount"}},
+				"keepSelector": "keep=true",
+			},
+			wantSuccess: true,
+			wantDeleted: []string{"pods/standalone", "configmaps/settings"},
+			wantOutputs: map[string]string{"deleted": "2", "kept": "4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			client := &mockClient{
+				discoverResourcesFn: func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+					return discovered, nil
+				},
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if namespace != "test" {
+						t.Errorf("expected list in namespace test, got %q", namespace)
+					}
+					return &unstructured.UnstructuredList{Items: contents[gvr.Resource]}, nil
+				},
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					return objectWithUID("uid-" + name), nil
+				},
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					deleted = append(deleted, gvr.Resource+"/"+name)
+					return nil
+				},
+			}
+
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+			}
+			if tt.clientNil {
+				ext.client = nil
+			}
+
+			result, err := ext.handleResetNamespace(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if !slices.Equal(deleted, tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("output %s = %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
@@ -18,6 +18,21 @@ type resourceRef struct {
 // parseResourceRef extracts resource reference info from operation arguments.
 // It validates that required fields (apiVersion, kind, metadata.name) are present.
 func parseResourceRef(args map[string]any) (*resourceRef, error) {
+	ref, err := parseResourceType(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.name == "" {
+		return nil, fmt.Errorf("metadata.name is required")
+	}
+
+	return ref, nil
+}
+
+// parseResourceType extracts resource reference info from operation arguments
+// like parseResourceRef, but does not require metadata.name.
+func parseResourceType(args map[string]any) (*resourceRef, error) {
 	apiVersion, _ := args["apiVersion"].(string)
 	kind, _ := args["kind"].(string)
 
@@ -38,10 +53,6 @@ func parseResourceRef(args map[string]any) (*resourceRef, error) {
 		ref.namespace, _ = metadata["namespace"].(string)
 	}
 
-	if ref.name == "" {
-		return nil, fmt.Errorf("metadata.name is required")
-	}
-
 	return ref, nil
 }
 
@@ -0,0 +1,254 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// defaultTokenExpiration is the lifetime of tokens requested for generated kubeconfigs.
+	defaultTokenExpiration = time.Hour
+	// minTokenExpiration is the shortest lifetime the TokenRequest API accepts.
+	minTokenExpiration = 10 * time.Minute
+)
+
+// tempFile is a file written by the extension. Files tied to a namespace, or
+// to a ServiceAccount in it, are removed when that object is cleaned up.
+type tempFile struct {
+	path           string
+	namespace      string
+	serviceAccount string
+}
+
+// trackTempFile records a file to remove at cleanup or when the extension stops.
+func (e *Extension) trackTempFile(f tempFile) {
+	e.mu.Lock()
+	e.tempFiles = append(e.tempFiles, f)
+	e.mu.Unlock()
+}
+
+// removeTempFilesFor removes the temporary files tied to a deleted object: all
+// files of a namespace, including sandbox kubeconfigs, or the kubeconfigs of a
+// ServiceAccount.
+func (e *Extension) removeTempFilesFor(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) {
+	if gvr == namespaceGVR {
+		namespace, name = name, ""
+	} else if gvr != serviceAccountGVR || namespace == "" {
+		return
+	}
+
+	e.mu.Lock()
+	var removed []tempFile
+	kept := e.tempFiles[:0]
+	for _, f := range e.tempFiles {
+		if f.namespace == namespace && (name == "" || f.serviceAccount == name) {
+			removed = append(removed, f)
+		} else {
+			kept = append(kept, f)
+		}
+	}
+	e.tempFiles = kept
+	e.sandboxKubeconfigs = slices.DeleteFunc(e.sandboxKubeconfigs, func(s sandboxKubeconfig) bool {
+		return slices.ContainsFunc(removed, func(f tempFile) bool { return f.path == s.path })
+	})
+	e.mu.Unlock()
+
+	for _, f := range removed {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			e.LogWarn(ctx, "Failed to remove temporary file", map[string]any{
+				"path":  f.path,
+				"error": err.Error(),
+			})
+			continue
+		}
+		e.LogInfo(ctx, "Removed temporary file", map[string]any{
+			"path":      f.path,
+			"namespace": f.namespace,
+		})
+	}
+}
+
+// currentCluster returns the name and entry of the cluster referenced by the
+// current context. Certificate authority files are inlined so that the entry
+// can be written to a kubeconfig in another directory.
+func (e *Extension) currentCluster(ctx context.Context) (string, *clientcmdapi.Cluster, error) {
+	raw, err := e.kube(ctx).ViewConfig(ctx, true)
+	if err != nil {
+		return "", nil, err
+	}
+	config, err := clientcmd.Load([]byte(raw))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	current, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return "", nil, fmt.Errorf("current context %q not found in kubeconfig", config.CurrentContext)
+	}
+	cluster, ok := config.Clusters[current.Cluster]
+	if !ok {
+		return "", nil, fmt.Errorf("cluster %q not found in kubeconfig", current.Cluster)
+	}
+
+	// Paths in the merged kubeconfig are already resolved against the file
+	// they were loaded from.
+	cluster = cluster.DeepCopy()
+	if cluster.CertificateAuthority != "" && len(cluster.CertificateAuthorityData) == 0 {
+		data, err := os.ReadFile(cluster.CertificateAuthority)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read certificate authority: %w", err)
+		}
+		cluster.CertificateAuthorityData = data
+		cluster.CertificateAuthority = ""
+	}
+	return current.Cluster, cluster, nil
+}
+
+// handleServiceAccountKubeconfig requests a bounded token for a ServiceAccount,
+// creating the ServiceAccount if needed, and writes a kubeconfig using it to a
+// temporary file. The file is removed when the ServiceAccount or its namespace
+// is cleaned up, and at the latest when the extension stops.
+func (e *Extension) handleServiceAccountKubeconfig(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	namespace, _ := args["namespace"].(string)
+	if namespace == "" {
+		return sdk.Failure(fmt.Errorf("namespace is required")), nil
+	}
+	name, _ := args["serviceAccount"].(string)
+	if name == "" {
+		return sdk.Failure(fmt.Errorf("serviceAccount is required")), nil
+	}
+
+	create := true
+	if c, ok := args["create"].(bool); ok {
+		create = c
+	}
+
+	expiration := defaultTokenExpiration
+	if expStr, _ := args["expiration"].(string); expStr != "" {
+		var err error
+		expiration, err = time.ParseDuration(expStr)
+		if err != nil {
+			return sdk.Failure(fmt.Errorf("invalid expiration: %w", err)), nil
+		}
+		if expiration < minTokenExpiration {
+			return sdk.Failure(fmt.Errorf("expiration must be at least %s", minTokenExpiration)), nil
+		}
+	}
+
+	audiences, err := stringListArg(args, "audiences")
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	clusterName, cluster, err := e.currentCluster(ctx)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to read current cluster: %w", err)), nil
+	}
+
+	created := false
+	if _, err := e.kube(ctx).Get(ctx, serviceAccountGVR, name, namespace); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return sdk.Failure(fmt.Errorf("failed to get ServiceAccount %s/%s: %w", namespace, name, err)), nil
+		}
+		if !create {
+			return sdk.Failure(fmt.Errorf("ServiceAccount %s/%s not found", namespace, name)), nil
+		}
+
+		sa := newObject("v1", "ServiceAccount", name, nil)
+		sa.SetNamespace(namespace)
+		e.applyRunLabels(sa)
+		result, err := e.kube(ctx).Create(ctx, serviceAccountGVR, sa, namespace)
+		if err != nil {
+			return sdk.Failure(fmt.Errorf("failed to create ServiceAccount %s/%s: %w", namespace, name, err)), nil
+		}
+		e.trackResource(ctx, serviceAccountGVR, result)
+		created = true
+	}
+
+	e.LogInfo(ctx, "Requesting ServiceAccount token", map[string]any{
+		"namespace":      namespace,
+		"serviceAccount": name,
+		"expiration":     expiration.String(),
+	})
+
+	status, err := e.kube(ctx).CreateToken(ctx, namespace, name, int64(expiration.Seconds()), audiences)
+	if err != nil {
+		e.LogError(ctx, "Failed to request token", map[string]any{
+			"error": err.Error(),
+		})
+		return sdk.Failure(fmt.Errorf("failed to request token for ServiceAccount %s/%s: %w", namespace, name, err)), nil
+	}
+
+	user := fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+	contextName := fmt.Sprintf("%s@%s", user, clusterName)
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = cluster
+	config.AuthInfos[user] = &clientcmdapi.AuthInfo{Token: status.Token}
+	config.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:   clusterName,
+		AuthInfo:  user,
+		Namespace: namespace,
+	}
+	config.CurrentContext = contextName
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to marshal kubeconfig: %w", err)), nil
+	}
+
+	// CreateTemp creates the file with mode 0600, which keeps the token private.
+	f, err := os.CreateTemp("", "mcpchecker-kubeconfig-*.yaml")
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to create kubeconfig file: %w", err)), nil
+	}
+	e.trackTempFile(tempFile{path: f.Name(), namespace: namespace, serviceAccount: name})
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to write kubeconfig: %w", err)), nil
+	}
+
+	expiresAt := ""
+	if !status.ExpirationTimestamp.IsZero() {
+		expiresAt = status.ExpirationTimestamp.UTC().Format(time.RFC3339)
+	}
+
+	e.LogInfo(ctx, "Wrote ServiceAccount kubeconfig", map[string]any{
+		"path":      f.Name(),
+		"context":   contextName,
+		"expiresAt": expiresAt,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Wrote kubeconfig for ServiceAccount %s/%s to %s", namespace, name, f.Name()),
+		map[string]string{
+			"kubeconfig":     f.Name(),
+			"context":        contextName,
+			"namespace":      namespace,
+			"serviceAccount": name,
+			"created":        fmt.Sprintf("%t", created),
+			"expiresAt":      expiresAt,
+		},
+	), nil
+}
@@ -0,0 +1,252 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestHandleServiceAccountKubeconfig(t *testing.T) {
+	const sourceConfig = `apiVersion: v1
+kind: Config
+current-context: admin
+contexts:
+- name: admin
+  context:
+    cluster: kind
+    user: admin
+clusters:
+- name: kind
+  cluster:
+    server: https://127.0.0.1:6443
+    certificate-authority-data: Y2E=
+users:
+- name: admin
+  user:
+    token: admin-token
+`
+	expiresAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		args           any
+		clientNil      bool
+		saExists       bool
+		tokenErr       error
+		wantSuccess    bool
+		wantErrContain string
+		wantCreated    bool
+		wantExpiration int64
+	}{
+		{
+			name:           "client not initialized",
+			args:           map[string]any{"namespace": "test", "serviceAccount": "agent"},
+			clientNil:      true,
+			wantErrContain: "kubernetes client not initialized",
+		},
+		{
+			name:           "missing service account",
+			args:           map[string]any{"namespace": "test"},
+			wantErrContain: "serviceAccount is required",
+		},
+		{
+			name:           "expiration too short",
+			args:           map[string]any{"namespace": "test", "serviceAccount": "agent", "expiration": "1m"},
+			wantErrContain: "expiration must be at least 10m0s",
+		},
+		{
+			name:           "existing service account",
+			args:           map[string]any{"namespace": "test", "serviceAccount": "agent", "expiration": "30m"},
+			saExists:       true,
+			wantSuccess:    true,
+			wantExpiration: 1800,
+		},
+		{
+			name:           "created service account",
+			args:           map[string]any{"namespace": "test", "serviceAccount": "agent"},
+			wantSuccess:    true,
+			wantCreated:    true,
+			wantExpiration: 3600,
+		},
+		{
+			name:           "missing service account without create",
+			args:           map[string]any{"namespace": "test", "serviceAccount": "agent", "create": false},
+			wantErrContain: "ServiceAccount test/agent not found",
+		},
+		{
+			name:           "token request error",
+			args:           map[string]any{"namespace": "test", "serviceAccount": "agent"},
+			saExists:       true,
+			tokenErr:       fmt.Errorf("forbidden"),
+			wantErrContain: "failed to request token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TMPDIR", t.TempDir())
+
+			var gotExpiration int64
+			client := &mockClient{
+				viewConfigFn: func(ctx context.Context, minify bool) (string, error) {
+					return sourceConfig, nil
+				},
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					if tt.saExists {
+						return &unstructured.Unstructured{}, nil
+					}
+					return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+				},
+				createTokenFn: func(ctx context.Context, namespace, serviceAccount string, expirationSeconds int64, audiences []string) (*authenticationv1.TokenRequestStatus, error) {
+					gotExpiration = expirationSeconds
+					if tt.tokenErr != nil {
+						return nil, tt.tokenErr
+					}
+					return &authenticationv1.TokenRequestStatus{Token: "sa-token", ExpirationTimestamp: metav1.NewTime(expiresAt)}, nil
+				},
+			}
+
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+			}
+			if tt.clientNil {
+				ext.client = nil
+			}
+
+			result, err := ext.handleServiceAccountKubeconfig(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if !tt.wantSuccess {
+				return
+			}
+
+			if gotExpiration != tt.wantExpiration {
+				t.Errorf("expirationSeconds = %d, want %d", gotExpiration, tt.wantExpiration)
+			}
+			if got := result.Outputs["created"]; got != fmt.Sprintf("%t", tt.wantCreated) {
+				t.Errorf("created = %q, want %t", got, tt.wantCreated)
+			}
+			if tt.wantCreated && len(ext.trackedResources) != 1 {
+				t.Errorf("expected the created ServiceAccount to be tracked, got %v", ext.trackedResources)
+			}
+			if got := result.Outputs["expiresAt"]; got != "2026-01-01T12:00:00Z" {
+				t.Errorf("expiresAt = %q", got)
+			}
+
+			path := result.Outputs["kubeconfig"]
+			config, err := clientcmd.LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("failed to load generated kubeconfig: %v", err)
+			}
+			current := config.Contexts[config.CurrentContext]
+			if current == nil || current.Namespace != "test" || current.Cluster != "kind" {
+				t.Fatalf("unexpected current context %q: %+v", config.CurrentContext, current)
+			}
+			if cluster := config.Clusters["kind"]; cluster.Server != "https://127.0.0.1:6443" || string(cluster.CertificateAuthorityData) != "ca" {
+				t.Errorf("unexpected cluster %+v", cluster)
+			}
+			if user := config.AuthInfos[current.AuthInfo]; user.Token != "sa-token" {
+				t.Errorf("token = %q, want sa-token", user.Token)
+			}
+			if _, ok := config.AuthInfos["admin"]; ok {
+				t.Error("generated kubeconfig must not contain the source credentials")
+			}
+
+			ext.removeTempFiles()
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("expected %s to be removed, got %v", path, err)
+			}
+		})
+	}
+}
+
+func TestTempFilesRemovedAtCleanup(t *testing.T) {
+	tests := []struct {
+		name        string
+		cleanup     func(ext *Extension)
+		wantRemoved []string
+	}{
+		{
+			name: "deleted tracked ServiceAccount",
+			cleanup: func(ext *Extension) {
+				ext.deleteTrackedResources(context.Background(), []trackedResource{
+					{gvr: serviceAccountGVR, kind: "ServiceAccount", namespace: "team-a", name: "agent"},
+				}, false, 0)
+			},
+			wantRemoved: []string{"team-a/agent"},
+		},
+		{
+			name: "deleted namespace",
+			cleanup: func(ext *Extension) {
+				ext.teardownNamespace(context.Background(), "team-a", false, false, 0)
+			},
+			wantRemoved: []string{"team-a/agent", "team-a/reader"},
+		},
+		{
+			name: "other resources keep the files",
+			cleanup: func(ext *Extension) {
+				ext.removeTempFilesFor(context.Background(), schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, "team-a", "agent")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client: &mockClient{
+					getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+						if gvr == namespaceGVR {
+							return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+						}
+						return &unstructured.Unstructured{Object: map[string]any{}}, nil
+					},
+				},
+			}
+			t.Cleanup(ext.removeTempFiles)
+
+			paths := map[string]string{}
+			for _, key := range []string{"team-a/agent", "team-a/reader", "team-b/agent"} {
+				namespace, name, _ := strings.Cut(key, "/")
+				paths[key] = fmt.Sprintf("%s/%s-%s.yaml", dir, namespace, name)
+				if err := os.WriteFile(paths[key], nil, 0o600); err != nil {
+					t.Fatalf("failed to write %s: %v", paths[key], err)
+				}
+				ext.trackTempFile(tempFile{path: paths[key], namespace: namespace, serviceAccount: name})
+			}
+
+			tt.cleanup(ext)
+
+			for key, path := range paths {
+				_, err := os.Stat(path)
+				if removed := slices.Contains(tt.wantRemoved, key); removed != os.IsNotExist(err) {
+					t.Errorf("%s: removed = %t, want %t", key, os.IsNotExist(err), removed)
+				}
+			}
+			if len(ext.tempFiles) != len(paths)-len(tt.wantRemoved) {
+				t.Errorf("expected %d tracked temp files, got %d", len(paths)-len(tt.wantRemoved), len(ext.tempFiles))
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// sandboxKubeconfig is a copy of the kubeconfig handed to an MCP server, and
+// its content when it was written.
+type sandboxKubeconfig struct {
+	path    string
+	initial []byte
+}
+
+// kubeconfigChange is a difference between two kubeconfigs. Credentials are
+// never reported, only that a user entry was modified.
+type kubeconfigChange struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name,omitempty"`
+	Change string `json:"change"`
+	Field  string `json:"field,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+func (c kubeconfigChange) String() string {
+	s := c.Kind
+	if c.Name != "" {
+		s += " " + c.Name
+	}
+	if c.Field == "" {
+		return s + ": " + c.Change
+	}
+	return fmt.Sprintf("%s: %s %q -> %q", s, c.Field, c.From, c.To)
+}
+
+// diffKubeconfigs returns the changes from before to after, sorted by kind and name.
+func diffKubeconfigs(before, after *clientcmdapi.Config) []kubeconfigChange {
+	var changes []kubeconfigChange
+	if before.CurrentContext != after.CurrentContext {
+		changes = append(changes, kubeconfigChange{
+			Kind: "current-context", Change: "modified", Field: "name",
+			From: before.CurrentContext, To: after.CurrentContext,
+		})
+	}
+
+	changes = append(changes, diffEntries("context", before.Contexts, after.Contexts, func(name string, b, a *clientcmdapi.Context) []kubeconfigChange {
+		var fields []kubeconfigChange
+		for _, f := range []struct{ field, from, to string }{
+			{"cluster", b.Cluster, a.Cluster},
+			{"user", b.AuthInfo, a.AuthInfo},
+			{"namespace", b.Namespace, a.Namespace},
+		} {
+			if f.from != f.to {
+				fields = append(fields, kubeconfigChange{Kind: "context", Name: name, Change: "modified", Field: f.field, From: f.from, To: f.to})
+			}
+		}
+		return fields
+	})...)
+
+	changes = append(changes, diffEntries("cluster", before.Clusters, after.Clusters, func(name string, b, a *clientcmdapi.Cluster) []kubeconfigChange {
+		if b.Server != a.Server {
+			return []kubeconfigChange{{Kind: "cluster", Name: name, Change: "modified", Field: "server", From: b.Server, To: a.Server}}
+		}
+		return nil
+	})...)
+
+	changes = append(changes, diffEntries("user", before.AuthInfos, after.AuthInfos, func(name string, b, a *clientcmdapi.AuthInfo) []kubeconfigChange {
+		return nil
+	})...)
+
+	return changes
+}
+
+// diffEntries compares the named entries of one kind. fields reports the
+// modified fields of an entry present in both; an entry that differs in any
+// other way is reported as modified without details.
+func diffEntries[T any](kind string, before, after map[string]*T, fields func(name string, b, a *T) []kubeconfigChange) []kubeconfigChange {
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var changes []kubeconfigChange
+	for _, name := range names {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		switch {
+		case !inAfter:
+			changes = append(changes, kubeconfigChange{Kind: kind, Name: name, Change: "removed"})
+		case !inBefore:
+			changes = append(changes, kubeconfigChange{Kind: kind, Name: name, Change: "added"})
+		case !reflect.DeepEqual(b, a):
+			modified := fields(name, b, a)
+			if len(modified) == 0 {
+				modified = []kubeconfigChange{{Kind: kind, Name: name, Change: "modified"}}
+			}
+			changes = append(changes, modified...)
+		}
+	}
+	return changes
+}
+
+// latestGeneratedNamespace returns the namespace most recently created by
+// createNamespace in the selected context, or an empty string when none was.
+func (e *Extension) latestGeneratedNamespace(ctx context.Context) string {
+	kubeContext := kubeContextName(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, ns := range slices.Backward(e.generatedNamespaces) {
+		if e.namespaceContexts[ns] == kubeContext {
+			return ns
+		}
+	}
+	return ""
+}
+
+// handleSandboxKubeconfig copies the kubeconfig to a temporary file for an MCP
+// server under test, so changes the agent makes do not reach the real
+// kubeconfig. The copy's current context is the selected context, and its
+// namespace defaults to the latest generated namespace. The copy is removed
+// when that namespace is deleted.
+func (e *Extension) handleSandboxKubeconfig(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	minify, _ := args["minify"].(bool)
+	namespace, _ := args["namespace"].(string)
+	generatedNamespace, explicit := args["generatedNamespace"].(bool)
+	if !explicit {
+		generatedNamespace = namespace == ""
+	}
+	// generated is the generated namespace the copy points to, if any.
+	var generated string
+	if generatedNamespace {
+		if namespace != "" {
+			return sdk.Failure(fmt.Errorf("namespace and generatedNamespace are mutually exclusive")), nil
+		}
+		generated = e.latestGeneratedNamespace(ctx)
+		if generated == "" && explicit {
+			return sdk.Failure(fmt.Errorf("no namespace has been generated with createNamespace in this context")), nil
+		}
+		namespace = generated
+	}
+
+	raw, err := e.kube(ctx).ViewConfig(ctx, minify)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to view config: %w", err)), nil
+	}
+	config, err := clientcmd.Load([]byte(raw))
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to parse kubeconfig: %w", err)), nil
+	}
+
+	// The copy starts in the selected context, as a minified config already does.
+	if name := kubeContextName(ctx); name != "" {
+		config.CurrentContext = name
+	} else if e.kubeContext != "" {
+		config.CurrentContext = e.kubeContext
+	}
+	current, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return sdk.Failure(fmt.Errorf("current context %q not found in kubeconfig", config.CurrentContext)), nil
+	}
+	if namespace != "" {
+		current.Namespace = namespace
+	}
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to marshal kubeconfig: %w", err)), nil
+	}
+
+	// CreateTemp creates the file with mode 0600, which keeps the credentials private.
+	f, err := os.CreateTemp("", "mcpchecker-sandbox-*.yaml")
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to create kubeconfig file: %w", err)), nil
+	}
+	e.trackTempFile(tempFile{path: f.Name(), namespace: generated})
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to write kubeconfig: %w", err)), nil
+	}
+
+	e.mu.Lock()
+	e.sandboxKubeconfigs = append(e.sandboxKubeconfigs, sandboxKubeconfig{path: f.Name(), initial: data})
+	e.mu.Unlock()
+
+	e.LogInfo(ctx, "Wrote sandbox kubeconfig", map[string]any{
+		"path":      f.Name(),
+		"context":   config.CurrentContext,
+		"namespace": current.Namespace,
+		"minify":    minify,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Wrote sandbox kubeconfig to %s", f.Name()),
+		map[string]string{
+			"kubeconfig": f.Name(),
+			"context":    config.CurrentContext,
+			"namespace":  current.Namespace,
+		},
+	), nil
+}
+
+// sandboxFor returns the sandbox kubeconfig at path, or the most recent one
+// when path is empty.
+func (e *Extension) sandboxFor(path string) (sandboxKubeconfig, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.sandboxKubeconfigs) == 0 {
+		return sandboxKubeconfig{}, fmt.Errorf("no sandbox kubeconfig has been written")
+	}
+	if path == "" {
+		return e.sandboxKubeconfigs[len(e.sandboxKubeconfigs)-1], nil
+	}
+	i := slices.IndexFunc(e.sandboxKubeconfigs, func(s sandboxKubeconfig) bool { return s.path == path })
+	if i < 0 {
+		return sandboxKubeconfig{}, fmt.Errorf("%s is not a sandbox kubeconfig", path)
+	}
+	return e.sandboxKubeconfigs[i], nil
+}
+
+// checkSandboxExpectations compares the sandbox kubeconfig against the expect
+// argument: changed, currentContext and namespace, the default namespace of
+// the current context.
+func checkSandboxExpectations(expect map[string]any, config *clientcmdapi.Config, changes []kubeconfigChange) []string {
+	var problems []string
+	if changed, ok := expect["changed"].(bool); ok && changed != (len(changes) > 0) {
+		if changed {
+			problems = append(problems, "expected the kubeconfig to be changed")
+		} else {
+			problems = append(problems, "expected the kubeconfig to be unchanged")
+		}
+	}
+	if want, ok := expect["currentContext"].(string); ok && config.CurrentContext != want {
+		problems = append(problems, fmt.Sprintf("current context is %q, expected %q", config.CurrentContext, want))
+	}
+	if want, ok := expect["namespace"].(string); ok {
+		namespace := ""
+		if current, ok := config.Contexts[config.CurrentContext]; ok {
+			namespace = current.Namespace
+		}
+		if namespace != want {
+			problems = append(problems, fmt.Sprintf("namespace of the current context is %q, expected %q", namespace, want))
+		}
+	}
+	return problems
+}
+
+// handleDiffSandboxKubeconfig reports how a sandbox kubeconfig changed since
+// sandboxKubeconfig wrote it.
+func (e *Extension) handleDiffSandboxKubeconfig(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	var expect map[string]any
+	if raw, ok := args["expect"]; ok {
+		if expect, ok = raw.(map[string]any); !ok {
+			return sdk.Failure(fmt.Errorf("expect must be an object")), nil
+		}
+	}
+
+	path, _ := args["path"].(string)
+	sandbox, err := e.sandboxFor(path)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	before, err := clientcmd.Load(sandbox.initial)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to parse initial kubeconfig: %w", err)), nil
+	}
+	data, err := os.ReadFile(sandbox.path)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to read sandbox kubeconfig: %w", err)), nil
+	}
+	after, err := clientcmd.Load(data)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to parse sandbox kubeconfig: %w", err)), nil
+	}
+
+	changes := diffKubeconfigs(before, after)
+
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to marshal changes: %w", err)), nil
+	}
+	outputs := map[string]string{
+		"kubeconfig":     sandbox.path,
+		"changed":        fmt.Sprintf("%t", len(changes) > 0),
+		"count":          fmt.Sprintf("%d", len(changes)),
+		"changes":        string(changesJSON),
+		"diff":           strings.Join(lines, "\n"),
+		"currentContext": after.CurrentContext,
+	}
+
+	e.LogInfo(ctx, "Compared sandbox kubeconfig", map[string]any{
+		"path":    sandbox.path,
+		"changes": len(changes),
+	})
+
+	summary := fmt.Sprintf("%d change(s) to %s", len(changes), sandbox.path)
+	if len(changes) > 0 {
+		summary += ":\n" + strings.Join(lines, "\n")
+	}
+
+	if problems := checkSandboxExpectations(expect, after, changes); len(problems) > 0 {
+		e.LogError(ctx, "Sandbox kubeconfig expectations not met", map[string]any{
+			"problems": problems,
+		})
+		return &sdk.OperationResult{
+			Success: false,
+			Message: fmt.Sprintf("%s\n%s", strings.Join(problems, "\n"), summary),
+			Error:   "sandbox kubeconfig expectations not met",
+			Outputs: outputs,
+		}, nil
+	}
+
+	return sdk.SuccessWithOutputs(summary, outputs), nil
+}
@@ -0,0 +1,327 @@
+package extension
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// sandboxTestExtension returns an extension whose kubeconfig merges the alpha
+// and beta files written by writeKubeconfig, with alpha as current context.
+func sandboxTestExtension(t *testing.T) *Extension {
+	t.Helper()
+	dir := t.TempDir()
+	loadingRules := &clientcmd.ClientConfigLoadingRules{Precedence: []string{
+		writeKubeconfig(t, dir, "alpha", "alpha"),
+		writeKubeconfig(t, dir, "beta", "beta"),
+	}}
+	ext := &Extension{
+		Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:    &dynamicClientAdapter{loadingRules: loadingRules},
+		newContextClient: func(name string) (ResourceClient, error) {
+			return &dynamicClientAdapter{loadingRules: loadingRules, contextName: name}, nil
+		},
+	}
+	t.Cleanup(ext.removeTempFiles)
+	return ext
+}
+
+func TestHandleSandboxKubeconfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           map[string]any
+		generated      []string
+		wantSuccess    bool
+		wantErrContain string
+		wantContext    string
+		wantNamespace  string
+		wantContexts   int
+	}{
+		{
+			name:          "full copy",
+			args:          map[string]any{},
+			wantSuccess:   true,
+			wantContext:   "alpha",
+			wantNamespace: "ns-alpha",
+			wantContexts:  2,
+		},
+		{
+			name:          "minified copy of selected context",
+			args:          map[string]any{"minify": true, "context": "beta"},
+			wantSuccess:   true,
+			wantContext:   "beta",
+			wantNamespace: "ns-beta",
+			wantContexts:  1,
+		},
+		{
+			name:          "full copy starts in selected context",
+			args:          map[string]any{"context": "beta", "namespace": "team-b"},
+			wantSuccess:   true,
+			wantContext:   "beta",
+			wantNamespace: "team-b",
+			wantContexts:  2,
+		},
+		{
+			name:          "defaults to the latest generated namespace",
+			args:          map[string]any{},
+			generated:     []string{"first-abc", "second-def"},
+			wantSuccess:   true,
+			wantContext:   "alpha",
+			wantNamespace: "second-def",
+			wantContexts:  2,
+		},
+		{
+			name:          "opt out of the generated namespace",
+			args:          map[string]any{"generatedNamespace": false},
+			generated:     []string{"first-abc"},
+			wantSuccess:   true,
+			wantContext:   "alpha",
+			wantNamespace: "ns-alpha",
+			wantContexts:  2,
+		},
+		{
+			name:          "namespace overrides the generated namespace",
+			args:          map[string]any{"namespace": "apps"},
+			generated:     []string{"first-abc"},
+			wantSuccess:   true,
+			wantContext:   "alpha",
+			wantNamespace: "apps",
+			wantContexts:  2,
+		},
+		{
+			name:          "generated namespace",
+			args:          map[string]any{"generatedNamespace": true},
+			generated:     []string{"first-abc", "second-def"},
+			wantSuccess:   true,
+			wantContext:   "alpha",
+			wantNamespace: "second-def",
+			wantContexts:  2,
+		},
+		{
+			name:           "no generated namespace",
+			args:           map[string]any{"generatedNamespace": true},
+			wantSuccess:    false,
+			wantErrContain: "no namespace has been generated",
+		},
+		{
+			name:           "namespace and generated namespace",
+			args:           map[string]any{"generatedNamespace": true, "namespace": "apps"},
+			generated:      []string{"first-abc"},
+			wantSuccess:    false,
+			wantErrContain: "mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := sandboxTestExtension(t)
+			ext.generatedNamespaces = tt.generated
+
+			result, err := ext.inContext(ext.handleSandboxKubeconfig)(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if !tt.wantSuccess {
+				if !strings.Contains(result.Error, tt.wantErrContain) {
+					t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+				}
+				return
+			}
+
+			path := result.Outputs["kubeconfig"]
+			config, err := clientcmd.LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("failed to load sandbox kubeconfig: %v", err)
+			}
+			if config.CurrentContext != tt.wantContext || len(config.Contexts) != tt.wantContexts {
+				t.Errorf("sandbox has current context %q and %d contexts, want %q and %d",
+					config.CurrentContext, len(config.Contexts), tt.wantContext, tt.wantContexts)
+			}
+			if ns := config.Contexts[config.CurrentContext].Namespace; ns != tt.wantNamespace || result.Outputs["namespace"] != tt.wantNamespace {
+				t.Errorf("namespace = %q (output %q), want %q", ns, result.Outputs["namespace"], tt.wantNamespace)
+			}
+			if ca := config.Clusters[tt.wantContext].CertificateAuthority; !filepath.IsAbs(ca) {
+				t.Errorf("certificate authority = %q, want an absolute path", ca)
+			}
+		})
+	}
+}
+
+func TestHandleDiffSandboxKubeconfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		modify         func(config *clientcmdapi.Config)
+		expect         map[string]any
+		wantSuccess    bool
+		wantChanges    []string
+		wantErrContain string
+	}{
+		{
+			name:        "unchanged",
+			expect:      map[string]any{"changed": false},
+			wantSuccess: true,
+		},
+		{
+			name: "switched context and namespace",
+			modify: func(config *clientcmdapi.Config) {
+				config.CurrentContext = "beta"
+				config.Contexts["beta"].Namespace = "team-b"
+			},
+			expect:      map[string]any{"changed": true, "currentContext": "beta", "namespace": "team-b"},
+			wantSuccess: true,
+			wantChanges: []string{
+				`current-context: name "alpha" -> "beta"`,
+				`context beta: namespace "ns-beta" -> "team-b"`,
+			},
+		},
+		{
+			name: "added and removed entries",
+			modify: func(config *clientcmdapi.Config) {
+				delete(config.Contexts, "beta")
+				config.Contexts["gamma"] = &clientcmdapi.Context{Cluster: "alpha", AuthInfo: "alpha"}
+				config.Clusters["alpha"].Server = "https://other.example.com"
+				config.AuthInfos["alpha"].Token = "new-secret-token"
+			},
+			wantSuccess: true,
+			wantChanges: []string{
+				"context beta: removed",
+				"context gamma: added",
+				`cluster alpha: server "https://alpha.example.com" -> "https://other.example.com"`,
+				"user alpha: modified",
+			},
+		},
+		{
+			name: "unexpected change",
+			modify: func(config *clientcmdapi.Config) {
+				config.CurrentContext = "beta"
+			},
+			expect:         map[string]any{"changed": false, "currentContext": "alpha"},
+			wantSuccess:    false,
+			wantErrContain: "expectations not met",
+			wantChanges:    []string{`current-context: name "alpha" -> "beta"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := sandboxTestExtension(t)
+			ctx := context.Background()
+
+			result, err := ext.handleSandboxKubeconfig(ctx, &sdk.OperationRequest{Args: map[string]any{}})
+			if err != nil || !result.Success {
+				t.Fatalf("sandboxKubeconfig failed: %v %s", err, result.Error)
+			}
+			path := result.Outputs["kubeconfig"]
+
+			if tt.modify != nil {
+				// Modify the copy the way kubectl config would.
+				access := &clientcmd.ClientConfigLoadingRules{ExplicitPath: path}
+				config, err := access.GetStartingConfig()
+				if err != nil {
+					t.Fatalf("failed to load sandbox kubeconfig: %v", err)
+				}
+				tt.modify(config)
+				if err := clientcmd.ModifyConfig(access, *config, false); err != nil {
+					t.Fatalf("failed to modify sandbox kubeconfig: %v", err)
+				}
+			}
+
+			args := map[string]any{"path": path}
+			if tt.expect != nil {
+				args["expect"] = tt.expect
+			}
+			result, err = ext.handleDiffSandboxKubeconfig(ctx, &sdk.OperationRequest{Args: args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error: %s, message: %s)", result.Success, tt.wantSuccess, result.Error, result.Message)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+			if diff := result.Outputs["diff"]; diff != strings.Join(tt.wantChanges, "\n") {
+				t.Errorf("diff =\n%s\nwant\n%s", diff, strings.Join(tt.wantChanges, "\n"))
+			}
+			if strings.Contains(result.Outputs["changes"], "secret-token") || strings.Contains(result.Message, "secret-token") {
+				t.Error("expected credentials to be left out of the changes")
+			}
+		})
+	}
+}
+
+func TestSandboxKubeconfigCleanup(t *testing.T) {
+	tests := []struct {
+		name        string
+		cleanup     func(ext *Extension) error
+		wantRemoved bool
+	}{
+		{
+			name: "generated namespace deleted",
+			cleanup: func(ext *Extension) error {
+				ext.teardownNamespace(context.Background(), "task-abc", false, false, 0)
+				return nil
+			},
+			wantRemoved: true,
+		},
+		{
+			name: "other namespace deleted",
+			cleanup: func(ext *Extension) error {
+				ext.teardownNamespace(context.Background(), "task-def", false, false, 0)
+				return nil
+			},
+		},
+		{
+			name: "deleteTracked",
+			cleanup: func(ext *Extension) error {
+				_, err := ext.handleDeleteTracked(context.Background(), &sdk.OperationRequest{Args: map[string]any{}})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := sandboxTestExtension(t)
+			ext.generatedNamespaces = []string{"task-abc"}
+			ctx := context.Background()
+
+			result, err := ext.handleSandboxKubeconfig(ctx, &sdk.OperationRequest{Args: map[string]any{}})
+			if err != nil || !result.Success {
+				t.Fatalf("sandboxKubeconfig failed: %v %s", err, result.Error)
+			}
+			path := result.Outputs["kubeconfig"]
+			// Namespace deletes go to a mock rather than the kubeconfig's clusters.
+			ext.client = &mockClient{}
+
+			if err := tt.cleanup(ext); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, statErr := os.Stat(path)
+			_, sandboxErr := ext.sandboxFor(path)
+			if removed := os.IsNotExist(statErr); removed != tt.wantRemoved || (sandboxErr == nil) == tt.wantRemoved {
+				t.Errorf("removed = %t (sandbox lookup: %v), want %t", removed, sandboxErr, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestDiffSandboxKubeconfigWithoutSandbox(t *testing.T) {
+	ext := sandboxTestExtension(t)
+	result, err := ext.handleDiffSandboxKubeconfig(context.Background(), &sdk.OperationRequest{Args: map[string]any{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "no sandbox kubeconfig") {
+		t.Errorf("expected a failure without a sandbox, got %+v", result)
+	}
+}
@@ -0,0 +1,295 @@
+package extension
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/yaml"
+)
+
+//go:embed scenarios/*.yaml
+var scenarioFS embed.FS
+
+var (
+	podGVR           = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	eventGVR         = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "events"}
+	endpointSliceGVR = schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}
+)
+
+// Symptom types describe how a scenario's failure becomes visible in the cluster.
+const (
+	// symptomContainerWaiting matches a container waiting with one of the given reasons.
+	symptomContainerWaiting = "containerWaiting"
+	// symptomPodUnschedulable matches a pod with PodScheduled=False and reason Unschedulable.
+	symptomPodUnschedulable = "podUnschedulable"
+	// symptomEvent matches an event with one of the given reasons involving a selected pod.
+	symptomEvent = "event"
+	// symptomServiceNoEndpoints matches a service without endpoints while the selected pods are ready.
+	symptomServiceNoEndpoints = "serviceNoEndpoints"
+)
+
+// scenario is a broken workload from the embedded catalog.
+type scenario struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	RootCause   string           `json:"rootCause"`
+	Symptom     scenarioSymptom  `json:"symptom"`
+	Objects     []map[string]any `json:"objects"`
+}
+
+// scenarioSymptom describes how to detect that a scenario's failure is visible.
+type scenarioSymptom struct {
+	Type     string   `json:"type"`
+	Selector string   `json:"selector"`
+	Reasons  []string `json:"reasons,omitempty"`
+	Service  string   `json:"service,omitempty"`
+}
+
+// loadScenarios parses the embedded scenario catalog, keyed by scenario name.
+func loadScenarios() (map[string]*scenario, error) {
+	entries, err := scenarioFS.ReadDir("scenarios")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario catalog: %w", err)
+	}
+
+	scenarios := make(map[string]*scenario, len(entries))
+	for _, entry := range entries {
+		data, err := scenarioFS.ReadFile(path.Join("scenarios", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read scenario %s: %w", entry.Name(), err)
+		}
+
+		var s scenario
+		if err := yaml.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("failed to parse scenario %s: %w", entry.Name(), err)
+		}
+		scenarios[s.Name] = &s
+	}
+
+	return scenarios, nil
+}
+
+// scenarioNames returns the sorted names of all scenarios in the catalog.
+func scenarioNames(scenarios map[string]*scenario) []string {
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleScenario instantiates a broken workload from the catalog into a namespace
+// and waits until its failure is visible in the cluster.
+func (e *Extension) handleScenario(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	name, _ := args["scenario"].(string)
+	if name == "" {
+		return sdk.Failure(fmt.Errorf("scenario is required")), nil
+	}
+
+	namespace, _ := args["namespace"].(string)
+	if namespace == "" {
+		return sdk.Failure(fmt.Errorf("namespace is required")), nil
+	}
+
+	timeoutStr, _ := args["timeout"].(string)
+	if timeoutStr == "" {
+		timeoutStr = "120s"
+	}
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("invalid timeout format: %w", err)), nil
+	}
+
+	scenarios, err := loadScenarios()
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	s, ok := scenarios[name]
+	if !ok {
+		return sdk.Failure(fmt.Errorf("unknown scenario %q (available: %s)", name, strings.Join(scenarioNames(scenarios), ", "))), nil
+	}
+
+	e.LogInfo(ctx, "Creating scenario", map[string]any{
+		"scenario":  s.Name,
+		"namespace": namespace,
+	})
+
+	objects := make([]string, 0, len(s.Objects))
+	for _, spec := range s.Objects {
+		obj := (&unstructured.Unstructured{Object: spec}).DeepCopy()
+		obj.SetNamespace(namespace)
+		e.applyRunLabels(obj)
+		gvk := obj.GroupVersionKind()
+		gvr := gvkToGVR(gvk)
+
+		result, err := e.kube(ctx).Create(ctx, gvr, obj, namespace)
+		if err != nil {
+			e.LogError(ctx, "Failed to create scenario object", map[string]any{
+				"scenario": s.Name,
+				"kind":     gvk.Kind,
+				"name":     obj.GetName(),
+				"error":    err.Error(),
+			})
+			return sdk.Failure(fmt.Errorf("failed to create %s/%s: %w", gvk.Kind, obj.GetName(), err)), nil
+		}
+		e.trackResource(ctx, gvr, result)
+		objects = append(objects, fmt.Sprintf("%s/%s", gvk.Kind, result.GetName()))
+	}
+
+	var pod, lastStatus string
+	err = wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		var visible bool
+		visible, pod, lastStatus = e.checkSymptom(ctx, namespace, s.Symptom)
+		return visible, nil
+	})
+	if err != nil {
+		e.LogError(ctx, "Scenario failure did not become visible", map[string]any{
+			"scenario":   s.Name,
+			"namespace":  namespace,
+			"lastStatus": lastStatus,
+		})
+		return sdk.FailureWithMessage(
+			fmt.Sprintf("Scenario %s did not reach its failure state", s.Name),
+			fmt.Errorf("timed out waiting for %s symptom: %s", s.Symptom.Type, lastStatus),
+		), nil
+	}
+
+	e.LogInfo(ctx, "Scenario failure is visible", map[string]any{
+		"scenario": s.Name,
+		"pod":      pod,
+		"status":   lastStatus,
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Scenario %s is failing in namespace %s: %s", s.Name, namespace, lastStatus),
+		map[string]string{
+			"scenario":  s.Name,
+			"namespace": namespace,
+			"objects":   strings.Join(objects, ","),
+			"pod":       pod,
+			"symptom":   lastStatus,
+			"rootCause": s.RootCause,
+		},
+	), nil
+}
+
+// checkSymptom reports whether the symptom is visible, the pod that shows it,
+// and a short status description for logging.
+func (e *Extension) checkSymptom(ctx context.Context, namespace string, symptom scenarioSymptom) (bool, string, string) {
+	pods, err := e.kube(ctx).List(ctx, podGVR, namespace, metav1.ListOptions{LabelSelector: symptom.Selector})
+	if err != nil {
+		return false, "", fmt.Sprintf("failed to list pods: %v", err)
+	}
+	if len(pods.Items) == 0 {
+		return false, "", "NoPods"
+	}
+
+	switch symptom.Type {
+	case symptomContainerWaiting:
+		for _, p := range pods.Items {
+			statuses, _, _ := unstructured.NestedSlice(p.Object, "status", "containerStatuses")
+			for _, s := range statuses {
+				status, ok := s.(map[string]any)
+				if !ok {
+					continue
+				}
+				reason, _, _ := unstructured.NestedString(status, "state", "waiting", "reason")
+				if slices.Contains(symptom.Reasons, reason) {
+					return true, p.GetName(), reason
+				}
+			}
+		}
+		return false, "", "ContainersNotWaiting"
+
+	case symptomPodUnschedulable:
+		for _, p := range pods.Items {
+			if cond := podCondition(&p, "PodScheduled"); cond != nil {
+				if cond["status"] == "False" && cond["reason"] == "Unschedulable" {
+					return true, p.GetName(), "Unschedulable"
+				}
+			}
+		}
+		return false, "", "PodNotUnschedulable"
+
+	case symptomEvent:
+		podNames := make(map[string]bool, len(pods.Items))
+		for _, p := range pods.Items {
+			podNames[p.GetName()] = true
+		}
+		events, err := e.kube(ctx).List(ctx, eventGVR, namespace, metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod"})
+		if err != nil {
+			return false, "", fmt.Sprintf("failed to list events: %v", err)
+		}
+		for _, ev := range events.Items {
+			reason, _, _ := unstructured.NestedString(ev.Object, "reason")
+			involved, _, _ := unstructured.NestedString(ev.Object, "involvedObject", "name")
+			if podNames[involved] && slices.Contains(symptom.Reasons, reason) {
+				return true, involved, reason
+			}
+		}
+		return false, "", "NoMatchingEvents"
+
+	case symptomServiceNoEndpoints:
+		var readyPod string
+		for _, p := range pods.Items {
+			if cond := podCondition(&p, "Ready"); cond != nil && cond["status"] == "True" {
+				readyPod = p.GetName()
+				break
+			}
+		}
+		if readyPod == "" {
+			return false, "", "PodsNotReady"
+		}
+		endpointSlices, err := e.kube(ctx).List(ctx, endpointSliceGVR, namespace, metav1.ListOptions{
+			LabelSelector: "kubernetes.io/service-name=" + symptom.Service,
+		})
+		if err != nil {
+			return false, "", fmt.Sprintf("failed to list endpoint slices: %v", err)
+		}
+		for _, slice := range endpointSlices.Items {
+			endpoints, _, _ := unstructured.NestedSlice(slice.Object, "endpoints")
+			if len(endpoints) > 0 {
+				return false, "", "ServiceHasEndpoints"
+			}
+		}
+		return true, readyPod, "ServiceHasNoEndpoints"
+
+	default:
+		return false, "", fmt.Sprintf("unknown symptom type %q", symptom.Type)
+	}
+}
+
+// podCondition returns the pod condition of the given type, or nil if it is not set.
+func podCondition(pod *unstructured.Unstructured, condType string) map[string]any {
+	conditions, _, _ := unstructured.NestedSlice(pod.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]any)
+		if ok && cond["type"] == condType {
+			return cond
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestLoadScenarios(t *testing.T) {
+	scenarios, err := loadScenarios()
+	if err != nil {
+		t.Fatalf("loadScenarios() error: %v", err)
+	}
+
+	want := []string{
+		"crash-loop-backoff",
+		"failing-readiness-probe",
+		"image-pull-backoff",
+		"missing-configmap",
+		"unschedulable-resources",
+		"wrong-service-selector",
+	}
+	if got := scenarioNames(scenarios); !slices.Equal(got, want) {
+		t.Errorf("scenarioNames() = %v, want %v", got, want)
+	}
+
+	knownSymptoms := []string{symptomContainerWaiting, symptomPodUnschedulable, symptomEvent, symptomServiceNoEndpoints}
+	for name, s := range scenarios {
+		if s.RootCause == "" {
+			t.Errorf("scenario %s has no rootCause", name)
+		}
+		if !slices.Contains(knownSymptoms, s.Symptom.Type) {
+			t.Errorf("scenario %s has unknown symptom type %q", name, s.Symptom.Type)
+		}
+		if s.Symptom.Selector == "" {
+			t.Errorf("scenario %s has no symptom selector", name)
+		}
+		if len(s.Objects) == 0 {
+			t.Errorf("scenario %s has no objects", name)
+		}
+		for _, obj := range s.Objects {
+			u := &unstructured.Unstructured{Object: obj}
+			if u.GetKind() == "" || u.GetName() == "" {
+				t.Errorf("scenario %s has an object without kind or name", name)
+			}
+		}
+	}
+}
+
+func waitingPod(name, reason string) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": name},
+		"status": map[string]any{
+			"containerStatuses": []any{
+				map[string]any{
+					"name":  "app",
+					"state": map[string]any{"waiting": map[string]any{"reason": reason}},
+				},
+			},
+		},
+	}}
+}
+
+func TestHandleScenario(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        any
+		client      *mockClient
+		wantSuccess bool
+		wantOutputs map[string]string
+	}{
+		{
+			name: "image pull failure becomes visible",
+			args: map[string]any{
+				"scenario":  "image-pull-backoff",
+				"namespace": "scenario-ns",
+			},
+			client: &mockClient{
+				createFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+					if namespace != "scenario-ns" || obj.GetNamespace() != "scenario-ns" {
+						t.Errorf("expected object in scenario-ns, got %q", namespace)
+					}
+					return obj, nil
+				},
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if opts.LabelSelector != "app=image-pull-backoff" {
+						t.Errorf("unexpected label selector %q", opts.LabelSelector)
+					}
+					return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+						waitingPod("image-pull-backoff-abc", "ImagePullBackOff"),
+					}}, nil
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{
+				"objects": "Deployment/image-pull-backoff",
+				"pod":     "image-pull-backoff-abc",
+				"symptom": "ImagePullBackOff",
+			},
+		},
+		{
+			name: "unschedulable pod",
+			args: map[string]any{
+				"scenario":  "unschedulable-resources",
+				"namespace": "scenario-ns",
+			},
+			client: &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+						{Object: map[string]any{
+							"metadata": map[string]any{"name": "pending-pod"},
+							"status": map[string]any{
+								"conditions": []any{
+									map[string]any{"type": "PodScheduled", "status": "False", "reason": "Unschedulable"},
+								},
+							},
+						}},
+					}}, nil
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{
+				"pod":     "pending-pod",
+				"symptom": "Unschedulable",
+			},
+		},
+		{
+			name: "wrong service selector",
+			args: map[string]any{
+				"scenario":  "wrong-service-selector",
+				"namespace": "scenario-ns",
+			},
+			client: &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if gvr == endpointSliceGVR {
+						return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+							{Object: map[string]any{"metadata": map[string]any{"name": "slice"}}},
+						}}, nil
+					}
+					return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+						{Object: map[string]any{
+							"metadata": map[string]any{"name": "ready-pod"},
+							"status": map[string]any{
+								"conditions": []any{
+									map[string]any{"type": "Ready", "status": "True"},
+								},
+							},
+						}},
+					}}, nil
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{
+				"objects": "Deployment/wrong-service-selector,Service/wrong-service-selector",
+				"symptom": "ServiceHasNoEndpoints",
+			},
+		},
+		{
+			name: "failure not visible within timeout",
+			args: map[string]any{
+				"scenario":  "crash-loop-backoff",
+				"namespace": "scenario-ns",
+				"timeout":   "1s",
+			},
+			client: &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+						waitingPod("crash-loop-backoff-abc", "ContainerCreating"),
+					}}, nil
+				},
+			},
+			wantSuccess: false,
+		},
+		{
+			name: "create error",
+			args: map[string]any{
+				"scenario":  "missing-configmap",
+				"namespace": "scenario-ns",
+			},
+			client: &mockClient{
+				createFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+					return nil, errors.New("namespace not found")
+				},
+			},
+			wantSuccess: false,
+		},
+		{
+			name: "unknown scenario",
+			args: map[string]any{
+				"scenario":  "does-not-exist",
+				"namespace": "scenario-ns",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name: "missing namespace",
+			args: map[string]any{
+				"scenario": "image-pull-backoff",
+			},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    tt.client,
+			}
+
+			req := &sdk.OperationRequest{Args: tt.args}
+			result, err := ext.handleScenario(context.Background(), req)
+
+			if err != nil {
+				t.Fatalf("handleScenario() returned error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("handleScenario() success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("handleScenario() output %s = %q, want %q", k, got, want)
+				}
+			}
+			if tt.wantSuccess && result.Outputs["rootCause"] == "" {
+				t.Errorf("handleScenario() rootCause output is empty")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package extension
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// defaultShutdownTimeout bounds the cleanup performed when the extension stops.
+const defaultShutdownTimeout = 30 * time.Second
+
+// shutdownCleanup deletes all tracked resources and generated namespaces when
+// the extension stops. The connection to mcpchecker is already closed at this
+// point, so progress is reported on stderr.
+func (e *Extension) shutdownCleanup() {
+	if e.client == nil {
+		return
+	}
+
+	e.mu.Lock()
+	resources := e.trackedResources
+	namespaces := e.generatedNamespaces
+	contexts := e.namespaceContexts
+	e.trackedResources = nil
+	e.generatedNamespaces = nil
+	e.namespaceContexts = nil
+	e.mu.Unlock()
+
+	if len(resources) == 0 && len(namespaces) == 0 {
+		return
+	}
+
+	timeout := e.shutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Printf("cleaning up %d tracked resource(s) and %d generated namespace(s) on shutdown (timeout %s)", len(resources), len(namespaces), timeout)
+
+	deleted, _, leftovers, errs := e.deleteTrackedResources(ctx, resources, false, 0)
+	for _, err := range errs {
+		log.Printf("shutdown cleanup: %s", err)
+	}
+	for _, r := range leftovers {
+		log.Printf("shutdown cleanup: left over %s", r)
+	}
+
+	failedNamespaces, nsErrs := e.deleteNamespaces(ctx, namespaces, contexts)
+	for _, err := range nsErrs {
+		log.Printf("shutdown cleanup: namespace %s", err)
+	}
+
+	// Leftovers and namespaces that could not be deleted stay in the state
+	// file, in creation order, so that gcStale in a later run can retry them.
+	if len(leftovers) > 0 || len(failedNamespaces) > 0 {
+		e.mu.Lock()
+		e.trackedResources = leftovers
+		e.generatedNamespaces = failedNamespaces
+		for _, ns := range failedNamespaces {
+			if kubeContext, ok := contexts[ns]; ok {
+				if e.namespaceContexts == nil {
+					e.namespaceContexts = make(map[string]string)
+				}
+				e.namespaceContexts[ns] = kubeContext
+			}
+		}
+		e.mu.Unlock()
+	}
+	if err := e.saveState(); err != nil {
+		log.Printf("shutdown cleanup: %v", err)
+	}
+
+	log.Printf("shutdown cleanup finished: deleted %d tracked resource(s), %d leftover(s), %d namespace error(s)", deleted, len(leftovers), len(nsErrs))
+}
+
+// removeTempFiles removes the temporary files written by the extension, such
+// as generated kubeconfigs, so that no credentials are left behind.
+func (e *Extension) removeTempFiles() {
+	e.mu.Lock()
+	files := e.tempFiles
+	e.tempFiles = nil
+	e.mu.Unlock()
+
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			log.Printf("shutdown cleanup: failed to remove %s: %v", f.path, err)
+		}
+	}
+}
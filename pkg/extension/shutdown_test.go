@@ -0,0 +1,133 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestShutdownCleanup(t *testing.T) {
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	tests := []struct {
+		name          string
+		tracked       []trackedResource
+		namespaces    []string
+		deleteErr     error
+		wantDeleted   []string
+		wantLeftovers int
+		// wantLeftoverNamespaces are the namespaces that stay tracked.
+		wantLeftoverNamespaces int
+	}{
+		{
+			name: "nothing to clean up",
+		},
+		{
+			name: "tracked resources before generated namespaces",
+			tracked: []trackedResource{
+				{gvr: configMapGVR, kind: "ConfigMap", namespace: "default", name: "first", uid: "uid"},
+				{gvr: configMapGVR, kind: "ConfigMap", namespace: "default", name: "second", uid: "uid"},
+			},
+			namespaces:  []string{"test-abc", "test-def"},
+			wantDeleted: []string{"configmaps/second", "configmaps/first", "namespaces/test-abc", "namespaces/test-def"},
+		},
+		{
+			name:                   "errors do not stop the cleanup",
+			tracked:                []trackedResource{{gvr: configMapGVR, kind: "ConfigMap", name: "cm", uid: "uid"}},
+			namespaces:             []string{"test-abc"},
+			deleteErr:              errors.New("connection refused"),
+			wantLeftovers:          1,
+			wantLeftoverNamespaces: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			client := &mockClient{
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					return objectWithUID("uid"), nil
+				},
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					if _, ok := ctx.Deadline(); !ok {
+						t.Error("expected shutdown cleanup to use a bounded context")
+					}
+					if tt.deleteErr != nil {
+						return tt.deleteErr
+					}
+					deleted = append(deleted, gvr.Resource+"/"+name)
+					return nil
+				},
+			}
+
+			ext := &Extension{
+				Extension:           sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:              client,
+				trackedResources:    tt.tracked,
+				generatedNamespaces: tt.namespaces,
+				shutdownTimeout:     5 * time.Second,
+			}
+
+			ext.shutdownCleanup()
+
+			if !slices.Equal(deleted, tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			if len(ext.trackedResources) != tt.wantLeftovers {
+				t.Errorf("expected %d leftover resource(s) tracked, got %d", tt.wantLeftovers, len(ext.trackedResources))
+			}
+			if len(ext.generatedNamespaces) != tt.wantLeftoverNamespaces {
+				t.Errorf("expected %d namespace(s) still tracked, got %v", tt.wantLeftoverNamespaces, ext.generatedNamespaces)
+			}
+		})
+	}
+}
+
+func TestShutdownCleanupKeepsFailedNamespaces(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	client := &mockClient{
+		deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+			if name == "test-abc" {
+				return nil
+			}
+			return errors.New("connection refused")
+		},
+	}
+	ext := &Extension{
+		Extension:           sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:              client,
+		newContextClient:    func(name string) (ResourceClient, error) { return client, nil },
+		runID:               "run-1",
+		stateFile:           statePath,
+		generatedNamespaces: []string{"test-abc", "test-def", "test-ghi"},
+		namespaceContexts:   map[string]string{"test-abc": "cluster-b", "test-ghi": "cluster-b"},
+		shutdownTimeout:     5 * time.Second,
+	}
+
+	ext.shutdownCleanup()
+
+	if want := []string{"test-def", "test-ghi"}; !slices.Equal(ext.generatedNamespaces, want) {
+		t.Errorf("generated namespaces = %v, want %v", ext.generatedNamespaces, want)
+	}
+	if want := map[string]string{"test-ghi": "cluster-b"}; !maps.Equal(ext.namespaceContexts, want) {
+		t.Errorf("namespace contexts = %v, want %v", ext.namespaceContexts, want)
+	}
+
+	state, err := loadState(statePath)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	run := state.Runs["run-1"]
+	if run == nil || !slices.Equal(run.Namespaces, []string{"test-def", "test-ghi"}) || run.NamespaceContexts["test-ghi"] != "cluster-b" {
+		t.Errorf("expected the failed namespaces to stay in the state file, got %+v", run)
+	}
+}
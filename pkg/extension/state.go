@@ -0,0 +1,227 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// persistedState is the content of the state file. Entries are keyed by run ID
+// so that several runs sharing a state file do not overwrite each other, and
+// gcStale can find what earlier runs left behind.
+type persistedState struct {
+	Runs map[string]*persistedRun `json:"runs"`
+}
+
+// persistedRun holds the tracked namespaces and resources of a single run.
+type persistedRun struct {
+	ExpiresAt  time.Time           `json:"expiresAt"`
+	Namespaces []string            `json:"namespaces,omitempty"`
+	Resources  []persistedResource `json:"resources,omitempty"`
+	// NamespaceContexts maps namespaces created outside the default
+	// kubeconfig context to their context.
+	NamespaceContexts map[string]string `json:"namespaceContexts,omitempty"`
+}
+
+// persistedResource is the serialized form of a trackedResource.
+type persistedResource struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Resource  string `json:"resource"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+	Context   string `json:"context,omitempty"`
+}
+
+func toPersistedResource(r trackedResource) persistedResource {
+	return persistedResource{
+		Group:     r.gvr.Group,
+		Version:   r.gvr.Version,
+		Resource:  r.gvr.Resource,
+		Kind:      r.kind,
+		Namespace: r.namespace,
+		Name:      r.name,
+		UID:       string(r.uid),
+		Context:   r.context,
+	}
+}
+
+func (p persistedResource) tracked() trackedResource {
+	return trackedResource{
+		gvr:       schema.GroupVersionResource{Group: p.Group, Version: p.Version, Resource: p.Resource},
+		kind:      p.Kind,
+		namespace: p.Namespace,
+		name:      p.Name,
+		uid:       types.UID(p.UID),
+		context:   p.Context,
+	}
+}
+
+// loadState reads the state file. A missing file yields an empty state.
+func loadState(path string) (*persistedState, error) {
+	state := &persistedState{Runs: make(map[string]*persistedRun)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if state.Runs == nil {
+		state.Runs = make(map[string]*persistedRun)
+	}
+	return state, nil
+}
+
+// writeState atomically replaces the state file.
+func writeState(path string, state *persistedState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+const (
+	// stateLockTimeout bounds how long a run waits for another run sharing the
+	// state file to finish updating it.
+	stateLockTimeout = 10 * time.Second
+	// staleStateLockAge is the age after which a lock is assumed to be left
+	// behind by a process that crashed while holding it.
+	staleStateLockAge = time.Minute
+)
+
+// lockStateFile takes an exclusive lock on the state file, shared by every
+// process using it, by creating a lock file next to it. The returned function
+// releases the lock.
+func lockStateFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(stateLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock state file: %w", err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleStateLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for state file lock %s", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// updateState applies update to the state file while holding its lock, so
+// that concurrent runs sharing the file do not lose each other's entries.
+func (e *Extension) updateState(update func(state *persistedState)) error {
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	unlock, err := lockStateFile(e.stateFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := loadState(e.stateFile)
+	if err != nil {
+		return err
+	}
+	update(state)
+	return writeState(e.stateFile, state)
+}
+
+// saveState records the current run's tracked namespaces and resources in the
+// state file, if one is configured. Errors are returned for logging only: a
+// failing state file must not fail the operation that changed the tracking.
+func (e *Extension) saveState() error {
+	if e.stateFile == "" {
+		return nil
+	}
+
+	e.mu.Lock()
+	run := &persistedRun{
+		ExpiresAt:  time.Now().Add(e.namespaceTTLOrDefault()).UTC(),
+		Namespaces: append([]string(nil), e.generatedNamespaces...),
+	}
+	for _, r := range e.trackedResources {
+		run.Resources = append(run.Resources, toPersistedResource(r))
+	}
+	for _, ns := range e.generatedNamespaces {
+		if kc, ok := e.namespaceContexts[ns]; ok {
+			if run.NamespaceContexts == nil {
+				run.NamespaceContexts = make(map[string]string)
+			}
+			run.NamespaceContexts[ns] = kc
+		}
+	}
+	e.mu.Unlock()
+
+	return e.updateState(func(state *persistedState) {
+		if len(run.Namespaces) == 0 && len(run.Resources) == 0 {
+			delete(state.Runs, e.runID)
+		} else {
+			state.Runs[e.runID] = run
+		}
+	})
+}
+
+// removeStateRuns drops the entries of the given runs from the state file.
+func (e *Extension) removeStateRuns(runIDs []string) error {
+	if e.stateFile == "" || len(runIDs) == 0 {
+		return nil
+	}
+
+	return e.updateState(func(state *persistedState) {
+		for _, id := range runIDs {
+			delete(state.Runs, id)
+		}
+	})
+}
+
+// persistState saves the state file and logs, rather than returns, any error.
+func (e *Extension) persistState(ctx context.Context) {
+	if err := e.saveState(); err != nil {
+		e.LogWarn(ctx, "Failed to save state file", map[string]any{
+			"path":  e.stateFile,
+			"error": err.Error(),
+		})
+	}
+}
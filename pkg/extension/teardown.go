@@ -0,0 +1,102 @@
+package extension
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Outcomes of tearing down a namespace.
+const (
+	namespaceDeleted     = "deleted"
+	namespaceAlreadyGone = "alreadyGone"
+	namespaceTerminating = "terminating"
+	namespaceFailed      = "error"
+)
+
+// defaultTeardownConcurrency is the number of namespaces deleted in parallel.
+const defaultTeardownConcurrency = 5
+
+// namespaceOutcome is the result of tearing down a single namespace.
+type namespaceOutcome struct {
+	name        string
+	status      string
+	err         string
+	termination namespaceTermination
+}
+
+// teardownNamespaces deletes the given namespaces with at most concurrency
+// deletions in flight, each in its kubeconfig context from contexts.
+// Outcomes are returned in the order of namespaces.
+func (e *Extension) teardownNamespaces(ctx context.Context, namespaces []string, contexts map[string]string, concurrency int, force, waitForDeletion bool, timeout time.Duration) []namespaceOutcome {
+	outcomes := make([]namespaceOutcome, len(namespaces))
+	forEachParallel(len(namespaces), concurrency, func(i int) {
+		nsCtx, err := e.withKubeContext(ctx, contexts[namespaces[i]])
+		if err != nil {
+			outcomes[i] = namespaceOutcome{name: namespaces[i], status: namespaceFailed, err: err.Error()}
+			return
+		}
+		outcomes[i] = e.teardownNamespace(nsCtx, namespaces[i], force, waitForDeletion, timeout)
+	})
+	return outcomes
+}
+
+// teardownNamespace deletes a namespace, optionally waits until it is gone and
+// then checks whether it is stuck terminating (removing finalizers with force).
+func (e *Extension) teardownNamespace(ctx context.Context, name string, force, waitForDeletion bool, timeout time.Duration) namespaceOutcome {
+	outcome := namespaceOutcome{name: name}
+
+	propagation := metav1.DeletePropagationForeground
+	err := e.kube(ctx).Delete(ctx, namespaceGVR, name, "", metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	})
+	if err == nil || apierrors.IsNotFound(err) {
+		e.removeTempFilesFor(ctx, namespaceGVR, "", name)
+	}
+	if apierrors.IsNotFound(err) {
+		outcome.status = namespaceAlreadyGone
+		return outcome
+	}
+	if err != nil {
+		e.LogError(ctx, "Failed to delete namespace", map[string]any{
+			"name":  name,
+			"error": err.Error(),
+		})
+		outcome.status = namespaceFailed
+		outcome.err = err.Error()
+		return outcome
+	}
+
+	// Without wait, the namespace is inspected once, unless force needs to give
+	// the namespace controller time to report what blocks it.
+	var settle time.Duration
+	switch {
+	case waitForDeletion:
+		settle = timeout
+	case force:
+		settle = min(timeout, defaultTerminationTimeout)
+	}
+
+	t, err := e.checkNamespaceTermination(ctx, name, force, waitForDeletion, settle)
+	outcome.termination = t
+	switch {
+	case err != nil:
+		outcome.status = namespaceFailed
+		outcome.err = err.Error()
+	case t.gone:
+		outcome.status = namespaceDeleted
+	default:
+		outcome.status = namespaceTerminating
+		if t.stuck() {
+			e.LogWarn(ctx, "Namespace is stuck terminating", map[string]any{
+				"name":       name,
+				"conditions": t.conditions,
+				"blocking":   t.blockingSummary(),
+			})
+		}
+	}
+
+	return outcome
+}
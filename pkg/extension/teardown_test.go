@@ -0,0 +1,207 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTeardownNamespacesConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	client := &mockClient{
+		deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				current := maxInFlight.Load()
+				if n <= current || maxInFlight.CompareAndSwap(current, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+	}
+
+	ext := &Extension{
+		Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client:    client,
+	}
+
+	namespaces := []string{"ns-1", "ns-2", "ns-3", "ns-4", "ns-5", "ns-6", "ns-7"}
+	outcomes := ext.teardownNamespaces(context.Background(), namespaces, nil, 3, false, false, time.Second)
+
+	if got := maxInFlight.Load(); got > 3 || got < 2 {
+		t.Errorf("expected between 2 and 3 deletions in flight, got %d", got)
+	}
+	for i, o := range outcomes {
+		if o.name != namespaces[i] {
+			t.Errorf("outcome %d is for %q, want %q", i, o.name, namespaces[i])
+		}
+		if o.status != namespaceDeleted {
+			t.Errorf("outcome for %s = %q, want %q", o.name, o.status, namespaceDeleted)
+		}
+	}
+}
+
+func TestTeardownNamespaceInspection(t *testing.T) {
+	tests := []struct {
+		name            string
+		force           bool
+		conditions      []string
+		wantInspections int32
+		wantFinalized   bool
+	}{
+		{
+			name:            "inspected once without wait or force",
+			wantInspections: 1,
+		},
+		{
+			name:       "force stops polling once the namespace is stuck",
+			force:      true,
+			conditions: []string{namespaceContentRemaining},
+			// Once to find it stuck and once to read it for the finalize call.
+			wantInspections: 2,
+			wantFinalized:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inspections atomic.Int32
+			finalized := false
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client: &mockClient{
+					getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+						if finalized {
+							return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+						}
+						inspections.Add(1)
+						return terminatingNamespace(name, tt.conditions...), nil
+					},
+					updateFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string, subresources ...string) (*unstructured.Unstructured, error) {
+						finalized = true
+						return obj, nil
+					},
+				},
+			}
+
+			start := time.Now()
+			outcome := ext.teardownNamespace(context.Background(), "ns-slow", tt.force, false, time.Minute)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("teardown took %s, expected no polling", elapsed)
+			}
+			if got := inspections.Load(); got != tt.wantInspections {
+				t.Errorf("inspected %d time(s), want %d", got, tt.wantInspections)
+			}
+			if finalized != tt.wantFinalized {
+				t.Errorf("finalized = %t, want %t (outcome %+v)", finalized, tt.wantFinalized, outcome)
+			}
+		})
+	}
+}
+
+func TestHandleDeleteGeneratedNamespacesOutcomes(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]any
+		wantSuccess bool
+		wantOutputs map[string]string
+		wantTracked []string
+	}{
+		{
+			name:        "outcomes without waiting",
+			args:        map[string]any{},
+			wantSuccess: false,
+			wantOutputs: map[string]string{
+				"outcomes":    `{"ns-broken":"error","ns-gone":"alreadyGone","ns-ok":"deleted","ns-slow":"terminating"}`,
+				"deleted":     "ns-ok",
+				"alreadyGone": "ns-gone",
+				"terminating": "ns-slow",
+				"failed":      "ns-broken",
+			},
+			wantTracked: []string{"ns-broken"},
+		},
+		{
+			name:        "waiting fails on namespaces still terminating",
+			args:        map[string]any{"wait": true, "timeout": "1ms", "concurrency": float64(2)},
+			wantSuccess: false,
+			wantOutputs: map[string]string{"terminating": "ns-slow"},
+			wantTracked: []string{"ns-broken"},
+		},
+		{
+			name:        "invalid concurrency",
+			args:        map[string]any{"concurrency": float64(0)},
+			wantSuccess: false,
+			wantTracked: []string{"ns-ok", "ns-gone", "ns-slow", "ns-broken"},
+		},
+		{
+			name:        "invalid timeout",
+			args:        map[string]any{"timeout": "soon"},
+			wantSuccess: false,
+			wantTracked: []string{"ns-ok", "ns-gone", "ns-slow", "ns-broken"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					switch name {
+					case "ns-gone":
+						return apierrors.NewNotFound(gvr.GroupResource(), name)
+					case "ns-broken":
+						return errors.New("permission denied")
+					}
+					return nil
+				},
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					if name == "ns-slow" {
+						return terminatingNamespace(name), nil
+					}
+					return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+				},
+			}
+
+			ext := &Extension{
+				Extension:           sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:              client,
+				generatedNamespaces: []string{"ns-ok", "ns-gone", "ns-slow", "ns-broken"},
+			}
+
+			result, err := ext.handleDeleteGeneratedNamespaces(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("output %s = %q, want %q", k, got, want)
+				}
+			}
+
+			ext.mu.Lock()
+			defer ext.mu.Unlock()
+			if len(ext.generatedNamespaces) != len(tt.wantTracked) {
+				t.Fatalf("tracked = %v, want %v", ext.generatedNamespaces, tt.wantTracked)
+			}
+			for i, ns := range tt.wantTracked {
+				if ext.generatedNamespaces[i] != ns {
+					t.Errorf("tracked = %v, want %v", ext.generatedNamespaces, tt.wantTracked)
+					break
+				}
+			}
+		})
+	}
+}
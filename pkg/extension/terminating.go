@@ -0,0 +1,249 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Namespace conditions set by the namespace controller while remaining content
+// prevents a terminating namespace from being removed.
+const (
+	namespaceContentRemaining    = "NamespaceContentRemaining"
+	namespaceFinalizersRemaining = "NamespaceFinalizersRemaining"
+)
+
+// defaultTerminationTimeout is how long a deleted namespace, or an object kept
+// by finalizers, is given to terminate on its own before it is reported as
+// stuck or, with force, has its finalizers removed.
+const defaultTerminationTimeout = 10 * time.Second
+
+// removeFinalizersPatch clears metadata.finalizers with a JSON merge patch.
+var removeFinalizersPatch = []byte(`{"metadata":{"finalizers":null}}`)
+
+// blockingObject is an object still present in a terminating namespace.
+type blockingObject struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespace  string
+	name       string
+	finalizers []string
+}
+
+func (o blockingObject) String() string {
+	s := fmt.Sprintf("%s %s/%s", o.kind, o.namespace, o.name)
+	if len(o.finalizers) > 0 {
+		s += fmt.Sprintf(" (finalizers: %s)", strings.Join(o.finalizers, ", "))
+	}
+	return s
+}
+
+// namespaceTermination describes the state of a namespace after it was deleted.
+type namespaceTermination struct {
+	name        string
+	gone        bool
+	terminating bool
+	// conditions holds "Type: message" for every blocking condition that is True.
+	conditions []string
+	blocking   []blockingObject
+	// finalized holds the objects whose finalizers were removed by force.
+	finalized []blockingObject
+}
+
+// stuck reports whether the namespace controller signals that remaining content
+// or finalizers block the removal of the namespace.
+func (t namespaceTermination) stuck() bool {
+	return !t.gone && len(t.conditions) > 0
+}
+
+// blockingSummary returns the blocking objects as a comma-separated list.
+func (t namespaceTermination) blockingSummary() string {
+	names := make([]string, len(t.blocking))
+	for i, o := range t.blocking {
+		names[i] = o.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// inspectNamespace reports whether a namespace is gone or still terminating and,
+// when NamespaceContentRemaining or NamespaceFinalizersRemaining is set, which
+// objects are left in it.
+func (e *Extension) inspectNamespace(ctx context.Context, name string) (namespaceTermination, error) {
+	t := namespaceTermination{name: name}
+
+	ns, err := e.kube(ctx).Get(ctx, namespaceGVR, name, "")
+	if apierrors.IsNotFound(err) {
+		t.gone = true
+		return t, nil
+	}
+	if err != nil {
+		return t, fmt.Errorf("failed to get namespace %s: %w", name, err)
+	}
+
+	t.terminating = ns.GetDeletionTimestamp() != nil
+
+	conditions, _, _ := unstructured.NestedSlice(ns.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		condType, _ := cond["type"].(string)
+		status, _ := cond["status"].(string)
+		message, _ := cond["message"].(string)
+		if (condType == namespaceContentRemaining || condType == namespaceFinalizersRemaining) && status == "True" {
+			t.conditions = append(t.conditions, fmt.Sprintf("%s: %s", condType, message))
+		}
+	}
+
+	if len(t.conditions) > 0 {
+		t.blocking, err = e.listBlockingObjects(ctx, name)
+		if err != nil {
+			return t, err
+		}
+	}
+
+	return t, nil
+}
+
+// listBlockingObjects lists every object left in a namespace. Resource types that
+// cannot be listed, e.g. because their aggregated API is unavailable, are skipped.
+func (e *Extension) listBlockingObjects(ctx context.Context, namespace string) ([]blockingObject, error) {
+	resources, err := e.discoverResources(ctx, "list")
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []blockingObject
+	for _, r := range resources {
+		if !r.namespaced {
+			continue
+		}
+		list, err := e.kube(ctx).List(ctx, r.gvr, namespace, metav1.ListOptions{})
+		if err != nil {
+			continue
+		}
+		for _, item := range list.Items {
+			objects = append(objects, blockingObject{
+				gvr:        r.gvr,
+				kind:       r.kind,
+				namespace:  namespace,
+				name:       item.GetName(),
+				finalizers: item.GetFinalizers(),
+			})
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].String() < objects[j].String()
+	})
+
+	return objects, nil
+}
+
+// removeFinalizers clears the finalizers of an object, ignoring objects that are already gone.
+func (e *Extension) removeFinalizers(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) error {
+	_, err := e.kube(ctx).Patch(ctx, gvr, name, namespace, types.MergePatchType, removeFinalizersPatch)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// forceFinalizeNamespace strips the finalizers from every object left in a
+// terminating namespace and then from the namespace itself, through its finalize
+// subresource. It returns the objects whose finalizers were removed.
+func (e *Extension) forceFinalizeNamespace(ctx context.Context, name string) ([]blockingObject, error) {
+	objects, err := e.listBlockingObjects(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var finalized []blockingObject
+	var errs []string
+	for _, o := range objects {
+		if len(o.finalizers) == 0 {
+			continue
+		}
+		if err := e.removeFinalizers(ctx, o.gvr, o.name, o.namespace); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", o, err.Error()))
+			continue
+		}
+		finalized = append(finalized, o)
+	}
+	if len(errs) > 0 {
+		return finalized, fmt.Errorf("failed to remove finalizers: %s", strings.Join(errs, "; "))
+	}
+
+	ns, err := e.kube(ctx).Get(ctx, namespaceGVR, name, "")
+	if apierrors.IsNotFound(err) {
+		return finalized, nil
+	}
+	if err != nil {
+		return finalized, fmt.Errorf("failed to get namespace %s: %w", name, err)
+	}
+
+	if err := unstructured.SetNestedStringSlice(ns.Object, []string{}, "spec", "finalizers"); err != nil {
+		return finalized, fmt.Errorf("failed to clear namespace finalizers: %w", err)
+	}
+	if _, err := e.kube(ctx).Update(ctx, namespaceGVR, ns, "", "finalize"); err != nil && !apierrors.IsNotFound(err) {
+		return finalized, fmt.Errorf("failed to finalize namespace %s: %w", name, err)
+	}
+
+	if len(ns.GetFinalizers()) > 0 {
+		if err := e.removeFinalizers(ctx, namespaceGVR, name, ""); err != nil {
+			return finalized, fmt.Errorf("failed to remove finalizers from namespace %s: %w", name, err)
+		}
+	}
+
+	return finalized, nil
+}
+
+// checkNamespaceTermination inspects a namespace after it was deleted, polling
+// for up to settle until it is gone or, unless waitForDeletion is set, until
+// it is stuck: still present with NamespaceContentRemaining or
+// NamespaceFinalizersRemaining set. With force, a stuck namespace has its
+// finalizers stripped and is inspected again; a namespace that is merely slow
+// to terminate is left alone.
+func (e *Extension) checkNamespaceTermination(ctx context.Context, name string, force, waitForDeletion bool, settle time.Duration) (namespaceTermination, error) {
+	deadline := time.Now().Add(settle)
+	var t namespaceTermination
+	var err error
+	for {
+		t, err = e.inspectNamespace(ctx, name)
+		if err != nil || t.gone || (t.stuck() && !waitForDeletion) || !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return t, nil
+		case <-time.After(time.Second):
+		}
+	}
+	if err != nil || !force || !t.stuck() {
+		return t, err
+	}
+
+	e.LogWarn(ctx, "Force-removing finalizers from stuck namespace", map[string]any{
+		"name":       name,
+		"conditions": t.conditions,
+	})
+
+	finalized, err := e.forceFinalizeNamespace(ctx, name)
+	if err != nil {
+		t.finalized = finalized
+		return t, err
+	}
+
+	after, err := e.inspectNamespace(ctx, name)
+	after.finalized = finalized
+	return after, err
+}
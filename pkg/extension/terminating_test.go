@@ -0,0 +1,179 @@
+package extension
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// terminatingNamespace returns a namespace in the Terminating phase with the given True conditions.
+func terminatingNamespace(name string, conditionTypes ...string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{Object: map[string]any{}}
+	ns.SetName(name)
+	ns.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	ns.SetFinalizers([]string{"example.com/ns-guard"})
+
+	var conditions []any
+	for _, condType := range conditionTypes {
+		conditions = append(conditions, map[string]any{
+			"type":    condType,
+			"status":  "True",
+			"message": "Some content remains",
+		})
+	}
+	ns.Object["spec"] = map[string]any{"finalizers": []any{"kubernetes"}}
+	ns.Object["status"] = map[string]any{"phase": "Terminating", "conditions": conditions}
+	return ns
+}
+
+func TestCheckNamespaceTermination(t *testing.T) {
+	widgetGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+	discovered := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace", Verbs: []string{"list"}},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: []string{"list"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		namespace     *unstructured.Unstructured
+		force         bool
+		wantGone      bool
+		wantStuck     bool
+		wantBlocking  string
+		wantPatched   []string
+		wantFinalized bool
+	}{
+		{
+			name:     "namespace already gone",
+			wantGone: true,
+		},
+		{
+			name:      "terminating without conditions",
+			namespace: terminatingNamespace("test-abc"),
+		},
+		{
+			name:         "stuck namespace lists blocking objects",
+			namespace:    terminatingNamespace("test-abc", namespaceContentRemaining, namespaceFinalizersRemaining),
+			wantStuck:    true,
+			wantBlocking: "ConfigMap test-abc/settings, Widget test-abc/gadget (finalizers: example.com/operator)",
+		},
+		{
+			name:          "force strips object and namespace finalizers",
+			namespace:     terminatingNamespace("test-abc", namespaceFinalizersRemaining),
+			force:         true,
+			wantGone:      true,
+			wantPatched:   []string{"widgets/gadget", "namespaces/test-abc"},
+			wantFinalized: true,
+		},
+		{
+			name:      "force leaves namespaces that are not stuck alone",
+			namespace: terminatingNamespace("test-abc"),
+			force:     true,
+		},
+		{
+			name: "force leaves active namespaces alone",
+			namespace: func() *unstructured.Unstructured {
+				ns := &unstructured.Unstructured{Object: map[string]any{}}
+				ns.SetName("test-abc")
+				return ns
+			}(),
+			force: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patched []string
+			finalized := false
+			client := &mockClient{
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					if tt.namespace == nil || finalized {
+						return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+					}
+					return tt.namespace.DeepCopy(), nil
+				},
+				discoverResourcesFn: func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+					return discovered, nil
+				},
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if namespace != "test-abc" {
+						t.Errorf("expected list in test-abc, got %q", namespace)
+					}
+					item := unstructured.Unstructured{Object: map[string]any{}}
+					switch gvr {
+					case widgetGVR:
+						item.SetName("gadget")
+						item.SetFinalizers([]string{"example.com/operator"})
+					default:
+						item.SetName("settings")
+					}
+					return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{item}}, nil
+				},
+				patchFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+					if patchType != types.MergePatchType || !strings.Contains(string(data), `"finalizers":null`) {
+						t.Errorf("unexpected patch %s %s", patchType, data)
+					}
+					patched = append(patched, gvr.Resource+"/"+name)
+					return &unstructured.Unstructured{Object: map[string]any{}}, nil
+				},
+				updateFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string, subresources ...string) (*unstructured.Unstructured, error) {
+					if gvr != namespaceGVR || !slices.Equal(subresources, []string{"finalize"}) {
+						t.Errorf("unexpected update of %s %v", gvr.Resource, subresources)
+					}
+					finalizers, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "finalizers")
+					if len(finalizers) != 0 {
+						t.Errorf("expected spec.finalizers cleared, got %v", finalizers)
+					}
+					finalized = true
+					return obj, nil
+				},
+			}
+
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+			}
+
+			got, err := ext.checkNamespaceTermination(context.Background(), "test-abc", tt.force, false, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.gone != tt.wantGone {
+				t.Errorf("gone = %v, want %v", got.gone, tt.wantGone)
+			}
+			if got.stuck() != tt.wantStuck {
+				t.Errorf("stuck = %v, want %v", got.stuck(), tt.wantStuck)
+			}
+			if summary := got.blockingSummary(); summary != tt.wantBlocking {
+				t.Errorf("blocking = %q, want %q", summary, tt.wantBlocking)
+			}
+			if !slices.Equal(patched, tt.wantPatched) {
+				t.Errorf("patched = %v, want %v", patched, tt.wantPatched)
+			}
+			if finalized != tt.wantFinalized {
+				t.Errorf("finalized = %v, want %v", finalized, tt.wantFinalized)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	podMetricsGVR  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	nodeMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+)
+
+const defaultTopLimit = 5
+
+// usageEntry holds the resource usage of a single pod or node.
+// For pods, usage is the sum over all containers.
+type usageEntry struct {
+	name          string
+	namespace     string
+	cpuMillicores int64
+	memoryBytes   int64
+}
+
+func (u usageEntry) cpu() string {
+	return fmt.Sprintf("%dm", u.cpuMillicores)
+}
+
+func (u usageEntry) memory() string {
+	return fmt.Sprintf("%dMi", u.memoryBytes/(1024*1024))
+}
+
+// handleTop queries metrics.k8s.io for pod or node usage, similar to kubectl top.
+// The top entry is exposed as individual outputs, the full ranking as a table.
+func (e *Extension) handleTop(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	resourceType, _ := args["resource"].(string)
+	if resourceType == "" {
+		resourceType = "pods"
+	}
+
+	var gvr schema.GroupVersionResource
+	switch resourceType {
+	case "pods", "pod":
+		gvr = podMetricsGVR
+	case "nodes", "node":
+		gvr = nodeMetricsGVR
+	default:
+		return sdk.Failure(fmt.Errorf("resource must be pods or nodes, got %q", resourceType)), nil
+	}
+
+	namespace, _ := args["namespace"].(string)
+	if gvr == nodeMetricsGVR {
+		namespace = ""
+	}
+	labelSelector, _ := args["labelSelector"].(string)
+
+	sortBy, _ := args["sortBy"].(string)
+	if sortBy == "" {
+		sortBy = "cpu"
+	}
+	if sortBy != "cpu" && sortBy != "memory" {
+		return sdk.Failure(fmt.Errorf("sortBy must be cpu or memory, got %q", sortBy)), nil
+	}
+
+	limit, hasLimit, err := intArg(args, "limit")
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+	if !hasLimit {
+		limit = defaultTopLimit
+	}
+	if limit < 1 {
+		return sdk.Failure(fmt.Errorf("limit must be at least 1")), nil
+	}
+
+	e.LogInfo(ctx, "Querying resource usage", map[string]any{
+		"resource":      gvr.Resource,
+		"namespace":     namespace,
+		"labelSelector": labelSelector,
+		"sortBy":        sortBy,
+		"limit":         limit,
+	})
+
+	list, err := e.kube(ctx).List(ctx, gvr, namespace, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+			e.LogError(ctx, "Metrics API not available", map[string]any{
+				"error": err.Error(),
+			})
+			return sdk.FailureWithMessage(
+				"Metrics API not available (is metrics-server installed?)",
+				fmt.Errorf("metrics.k8s.io is not served by the cluster: %w", err),
+			), nil
+		}
+		e.LogError(ctx, "Failed to query metrics", map[string]any{
+			"error": err.Error(),
+		})
+		return sdk.Failure(fmt.Errorf("failed to query metrics: %w", err)), nil
+	}
+
+	entries := make([]usageEntry, 0, len(list.Items))
+	for i := range list.Items {
+		entry, err := parseUsage(&list.Items[i], gvr == podMetricsGVR)
+		if err != nil {
+			return sdk.Failure(err), nil
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return sdk.Failure(fmt.Errorf("no %s metrics found", gvr.Resource)), nil
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if sortBy == "memory" {
+			return entries[i].memoryBytes > entries[j].memoryBytes
+		}
+		return entries[i].cpuMillicores > entries[j].cpuMillicores
+	})
+
+	total := len(entries)
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	names := make([]string, 0, len(entries))
+	var table strings.Builder
+	fmt.Fprintf(&table, "%-4s %-50s %-10s %-10s\n", "RANK", "NAME", "CPU", "MEMORY")
+	for i, entry := range entries {
+		name := entry.name
+		if entry.namespace != "" {
+			name = entry.namespace + "/" + entry.name
+		}
+		names = append(names, name)
+		fmt.Fprintf(&table, "%-4d %-50s %-10s %-10s\n", i+1, name, entry.cpu(), entry.memory())
+	}
+
+	top := entries[0]
+	e.LogInfo(ctx, "Resource usage retrieved", map[string]any{
+		"count":  total,
+		"top":    names[0],
+		"cpu":    top.cpu(),
+		"memory": top.memory(),
+	})
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Top %s by %s: %s (cpu: %s, memory: %s)", gvr.Resource, sortBy, names[0], top.cpu(), top.memory()),
+		map[string]string{
+			"name":          top.name,
+			"namespace":     top.namespace,
+			"cpu":           top.cpu(),
+			"memory":        top.memory(),
+			"cpuMillicores": fmt.Sprintf("%d", top.cpuMillicores),
+			"memoryBytes":   fmt.Sprintf("%d", top.memoryBytes),
+			"names":         strings.Join(names, ","),
+			"count":         fmt.Sprintf("%d", total),
+			"table":         table.String(),
+		},
+	), nil
+}
+
+// parseUsage extracts CPU and memory usage from a PodMetrics or NodeMetrics object.
+func parseUsage(obj *unstructured.Unstructured, isPod bool) (usageEntry, error) {
+	entry := usageEntry{
+		name:      obj.GetName(),
+		namespace: obj.GetNamespace(),
+	}
+
+	var usages []map[string]any
+	if isPod {
+		containers, _, _ := unstructured.NestedSlice(obj.Object, "containers")
+		for _, c := range containers {
+			container, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			if usage, ok := container["usage"].(map[string]any); ok {
+				usages = append(usages, usage)
+			}
+		}
+	} else if usage, ok := obj.Object["usage"].(map[string]any); ok {
+		usages = append(usages, usage)
+	}
+
+	for _, usage := range usages {
+		if cpu, ok := usage["cpu"].(string); ok {
+			q, err := resource.ParseQuantity(cpu)
+			if err != nil {
+				return entry, fmt.Errorf("invalid cpu usage %q for %s: %w", cpu, entry.name, err)
+			}
+			entry.cpuMillicores += q.MilliValue()
+		}
+		if memory, ok := usage["memory"].(string); ok {
+			q, err := resource.ParseQuantity(memory)
+			if err != nil {
+				return entry, fmt.Errorf("invalid memory usage %q for %s: %w", memory, entry.name, err)
+			}
+			entry.memoryBytes += q.Value()
+		}
+	}
+
+	return entry, nil
+}
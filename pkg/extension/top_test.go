@@ -0,0 +1,170 @@
+package extension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func podMetrics(name, namespace string, usages ...map[string]any) unstructured.Unstructured {
+	containers := make([]any, 0, len(usages))
+	for _, u := range usages {
+		containers = append(containers, map[string]any{"name": "c", "usage": u})
+	}
+	return unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]any{"name": name, "namespace": namespace},
+		"containers": containers,
+	}}
+}
+
+func TestHandleTop(t *testing.T) {
+	pods := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		podMetrics("small", "default", map[string]any{"cpu": "500m", "memory": "64Mi"}),
+		podMetrics("hungry", "default",
+			map[string]any{"cpu": "10m", "memory": "256Mi"},
+			map[string]any{"cpu": "5m", "memory": "256Mi"},
+		),
+	}}
+
+	tests := []struct {
+		name        string
+		args        any
+		client      *mockClient
+		wantSuccess bool
+		wantOutputs map[string]string
+	}{
+		{
+			name: "pods sorted by memory",
+			args: map[string]any{
+				"namespace":     "default",
+				"labelSelector": "app=web",
+				"sortBy":        "memory",
+			},
+			client: &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if gvr != podMetricsGVR || namespace != "default" || opts.LabelSelector != "app=web" {
+						t.Errorf("unexpected list call: %v %s %+v", gvr, namespace, opts)
+					}
+					return pods, nil
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{
+				"name":        "hungry",
+				"cpu":         "15m",
+				"memory":      "512Mi",
+				"memoryBytes": "536870912",
+				"names":       "default/hungry,default/small",
+				"count":       "2",
+			},
+		},
+		{
+			name: "pods sorted by cpu with limit",
+			args: map[string]any{
+				"limit": float64(1),
+			},
+			client: &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					return pods, nil
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{
+				"name":  "small",
+				"cpu":   "500m",
+				"names": "default/small",
+				"count": "2",
+			},
+		},
+		{
+			name: "nodes",
+			args: map[string]any{
+				"resource": "nodes",
+			},
+			client: &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if gvr != nodeMetricsGVR {
+						t.Errorf("expected node metrics, got %v", gvr)
+					}
+					return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+						{Object: map[string]any{
+							"metadata": map[string]any{"name": "node-1"},
+							"usage":    map[string]any{"cpu": "1", "memory": "1Gi"},
+						}},
+					}}, nil
+				},
+			},
+			wantSuccess: true,
+			wantOutputs: map[string]string{
+				"name":   "node-1",
+				"cpu":    "1000m",
+				"memory": "1024Mi",
+			},
+		},
+		{
+			name: "metrics-server not installed",
+			args: map[string]any{},
+			client: &mockClient{
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					return nil, apierrors.NewNotFound(schema.GroupResource{Group: "metrics.k8s.io", Resource: "pods"}, "")
+				},
+			},
+			wantSuccess: false,
+		},
+		{
+			name:        "no metrics found",
+			args:        map[string]any{},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name:        "invalid resource",
+			args:        map[string]any{"resource": "deployments"},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name:        "invalid sortBy",
+			args:        map[string]any{"sortBy": "name"},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+		{
+			name:        "invalid limit",
+			args:        map[string]any{"limit": 1.5},
+			client:      &mockClient{},
+			wantSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    tt.client,
+			}
+
+			req := &sdk.OperationRequest{Args: tt.args}
+			result, err := ext.handleTop(context.Background(), req)
+
+			if err != nil {
+				t.Fatalf("handleTop() returned error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("handleTop() success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("handleTop() output %s = %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// trackedResource identifies an object created by the extension so it can be
+// deleted at cleanup. The UID guards against deleting an object that was
+// replaced by another one with the same name.
+type trackedResource struct {
+	gvr       schema.GroupVersionResource
+	kind      string
+	namespace string
+	name      string
+	uid       types.UID
+	// context is the kubeconfig context the object was created in, or empty
+	// for the default context.
+	context string
+}
+
+func (r trackedResource) String() string {
+	s := fmt.Sprintf("%s %s", r.kind, r.name)
+	if r.namespace != "" {
+		s = fmt.Sprintf("%s %s/%s", r.kind, r.namespace, r.name)
+	}
+	if r.context != "" {
+		s += " in context " + r.context
+	}
+	return s
+}
+
+// trackResource records a created object for deleteTracked.
+func (e *Extension) trackResource(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) {
+	e.mu.Lock()
+	e.trackedResources = append(e.trackedResources, trackedResource{
+		gvr:       gvr,
+		kind:      obj.GetKind(),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+		uid:       obj.GetUID(),
+		context:   kubeContextName(ctx),
+	})
+	e.mu.Unlock()
+
+	e.persistState(ctx)
+}
+
+// handleDeleteTracked deletes all tracked objects in reverse creation order.
+func (e *Extension) handleDeleteTracked(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	waitForDeletion, _ := args["wait"].(bool)
+
+	timeoutStr, _ := args["timeout"].(string)
+	if timeoutStr == "" {
+		timeoutStr = "60s"
+	}
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("invalid timeout format: %w", err)), nil
+	}
+
+	e.mu.Lock()
+	resources := e.trackedResources
+	e.trackedResources = nil
+	e.mu.Unlock()
+
+	if len(resources) == 0 {
+		return sdk.Success("No tracked resources to delete"), nil
+	}
+
+	e.LogInfo(ctx, "Deleting tracked resources", map[string]any{
+		"count": len(resources),
+		"wait":  waitForDeletion,
+	})
+
+	deleted, skipped, leftovers, errs := e.deleteTrackedResources(ctx, resources, waitForDeletion, timeout)
+	defer e.persistState(ctx)
+
+	outputs := map[string]string{
+		"deleted":   fmt.Sprintf("%d", deleted),
+		"skipped":   fmt.Sprintf("%d", skipped),
+		"leftovers": fmt.Sprintf("%d", len(leftovers)),
+	}
+
+	if len(leftovers) > 0 {
+		// Keep leftovers tracked, in creation order, so a later deleteTracked can retry them.
+		e.mu.Lock()
+		e.trackedResources = append(slices.Clone(leftovers), e.trackedResources...)
+		e.mu.Unlock()
+
+		names := make([]string, 0, len(leftovers))
+		for _, r := range leftovers {
+			names = append(names, r.String())
+		}
+		outputs["leftoverResources"] = strings.Join(names, ", ")
+
+		msg := fmt.Sprintf("%d tracked resource(s) left over: %s", len(leftovers), strings.Join(names, ", "))
+		if len(errs) > 0 {
+			return &sdk.OperationResult{
+				Success: false,
+				Message: msg,
+				Error:   fmt.Sprintf("failed to delete tracked resources: %s", strings.Join(errs, "; ")),
+				Outputs: outputs,
+			}, nil
+		}
+		return &sdk.OperationResult{
+			Success: false,
+			Message: msg,
+			Error:   "timed out waiting for tracked resources to be deleted",
+			Outputs: outputs,
+		}, nil
+	}
+
+	return sdk.SuccessWithOutputs(
+		fmt.Sprintf("Deleted %d tracked resource(s), skipped %d", deleted, skipped),
+		outputs,
+	), nil
+}
+
+// deleteTrackedResources deletes resources in reverse order. Objects that are
+// already gone or whose UID changed are skipped. When waitForDeletion is set,
+// it polls until the deleted objects are gone or the timeout expires.
+// Resources that could not be deleted or are still present are returned as
+// leftovers, in creation order.
+func (e *Extension) deleteTrackedResources(ctx context.Context, resources []trackedResource, waitForDeletion bool, timeout time.Duration) (int, int, []trackedResource, []string) {
+	var (
+		deleted, skipped int
+		// pending and leftovers are indexes into resources.
+		pending   []int
+		leftovers []int
+		errs      []string
+	)
+
+	propagation := metav1.DeletePropagationForeground
+	for i := len(resources) - 1; i >= 0; i-- {
+		r := resources[i]
+
+		client, err := e.clientForContext(r.context)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", r, err.Error()))
+			leftovers = append(leftovers, i)
+			continue
+		}
+
+		current, err := client.Get(ctx, r.gvr, r.name, r.namespace)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				e.LogInfo(ctx, "Tracked resource already deleted (ignored)", map[string]any{
+					"resource": r.String(),
+				})
+				skipped++
+				continue
+			}
+			errs = append(errs, fmt.Sprintf("%s: %s", r, err.Error()))
+			leftovers = append(leftovers, i)
+			continue
+		}
+
+		if r.uid != "" && current.GetUID() != r.uid {
+			e.LogInfo(ctx, "Tracked resource was replaced (skipped)", map[string]any{
+				"resource": r.String(),
+				"uid":      string(r.uid),
+				"current":  string(current.GetUID()),
+			})
+			skipped++
+			continue
+		}
+
+		deleteOpts := metav1.DeleteOptions{
+			PropagationPolicy: &propagation,
+		}
+		if r.uid != "" {
+			deleteOpts.Preconditions = &metav1.Preconditions{UID: &r.uid}
+		}
+
+		if err := client.Delete(ctx, r.gvr, r.name, r.namespace, deleteOpts); err != nil {
+			if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+				skipped++
+				continue
+			}
+			e.LogError(ctx, "Failed to delete tracked resource", map[string]any{
+				"resource": r.String(),
+				"error":    err.Error(),
+			})
+			errs = append(errs, fmt.Sprintf("%s: %s", r, err.Error()))
+			leftovers = append(leftovers, i)
+			continue
+		}
+
+		deleted++
+		pending = append(pending, i)
+		e.removeTempFilesFor(ctx, r.gvr, r.namespace, r.name)
+	}
+
+	if waitForDeletion && len(pending) > 0 {
+		_ = wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			remaining := pending[:0]
+			for _, i := range pending {
+				r := resources[i]
+				client, err := e.clientForContext(r.context)
+				if err != nil {
+					remaining = append(remaining, i)
+					continue
+				}
+				current, err := client.Get(ctx, r.gvr, r.name, r.namespace)
+				if apierrors.IsNotFound(err) || (err == nil && r.uid != "" && current.GetUID() != r.uid) {
+					continue
+				}
+				remaining = append(remaining, i)
+			}
+			pending = remaining
+			return len(pending) == 0, nil
+		})
+
+		for _, i := range pending {
+			e.LogError(ctx, "Tracked resource still present", map[string]any{
+				"resource": resources[i].String(),
+			})
+		}
+		leftovers = append(leftovers, pending...)
+	}
+
+	// Objects that failed to delete and objects still present were collected
+	// separately, so sort them back into creation order.
+	slices.Sort(leftovers)
+	result := make([]trackedResource, len(leftovers))
+	for j, i := range leftovers {
+		result[j] = resources[i]
+	}
+	return deleted, skipped, result, errs
+}
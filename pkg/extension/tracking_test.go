@@ -0,0 +1,227 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func objectWithUID(uid string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	obj.SetUID(types.UID(uid))
+	return obj
+}
+
+func TestHandleCreateTracksResource(t *testing.T) {
+	ext := &Extension{
+		Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+		client: &mockClient{
+			createFn: func(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+				result := obj.DeepCopy()
+				result.SetUID("cm-uid")
+				return result, nil
+			},
+		},
+	}
+
+	req := &sdk.OperationRequest{Args: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "test-cm", "namespace": "default"},
+	}}
+	if result, _ := ext.handleCreate(context.Background(), req); !result.Success {
+		t.Fatalf("handleCreate() failed: %s", result.Error)
+	}
+
+	want := trackedResource{
+		gvr:       schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		kind:      "ConfigMap",
+		namespace: "default",
+		name:      "test-cm",
+		uid:       "cm-uid",
+	}
+	if len(ext.trackedResources) != 1 || ext.trackedResources[0] != want {
+		t.Errorf("trackedResources = %+v, want [%+v]", ext.trackedResources, want)
+	}
+}
+
+func TestHandleDeleteTracked(t *testing.T) {
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	tracked := []trackedResource{
+		{gvr: configMapGVR, kind: "ConfigMap", namespace: "default", name: "first", uid: "uid-1"},
+		{gvr: configMapGVR, kind: "ConfigMap", namespace: "default", name: "second", uid: "uid-2"},
+		{gvr: configMapGVR, kind: "ConfigMap", namespace: "default", name: "third", uid: "uid-3"},
+	}
+
+	tests := []struct {
+		name          string
+		args          any
+		tracked       []trackedResource
+		getFn         func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error)
+		deleteErr     map[string]error
+		wantSuccess   bool
+		wantDeleted   []string
+		wantOutputs   map[string]string
+		wantRetracked []string
+	}{
+		{
+			name:        "nothing tracked",
+			args:        map[string]any{},
+			wantSuccess: true,
+		},
+		{
+			name:    "deletes in reverse creation order",
+			args:    map[string]any{},
+			tracked: tracked,
+			getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+				return objectWithUID(map[string]string{"first": "uid-1", "second": "uid-2", "third": "uid-3"}[name]), nil
+			},
+			wantSuccess: true,
+			wantDeleted: []string{"third", "second", "first"},
+			wantOutputs: map[string]string{"deleted": "3", "skipped": "0", "leftovers": "0"},
+		},
+		{
+			name:    "skips replaced and missing objects",
+			args:    map[string]any{},
+			tracked: tracked,
+			getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+				switch name {
+				case "first":
+					return objectWithUID("uid-1"), nil
+				case "second":
+					return objectWithUID("recreated"), nil
+				}
+				return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+			},
+			wantSuccess: true,
+			wantDeleted: []string{"first"},
+			wantOutputs: map[string]string{"deleted": "1", "skipped": "2"},
+		},
+		{
+			name:    "delete error reports leftovers and keeps them tracked",
+			args:    map[string]any{},
+			tracked: tracked,
+			getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+				return objectWithUID(map[string]string{"first": "uid-1", "second": "uid-2", "third": "uid-3"}[name]), nil
+			},
+			deleteErr: map[string]error{
+				"first": errors.New("forbidden"),
+				"third": errors.New("forbidden"),
+			},
+			wantSuccess:   false,
+			wantDeleted:   []string{"second"},
+			wantOutputs:   map[string]string{"deleted": "1", "leftovers": "2"},
+			wantRetracked: []string{"first", "third"},
+		},
+		{
+			name:    "wait until gone",
+			args:    map[string]any{"wait": true, "timeout": "5s"},
+			tracked: tracked[:1],
+			getFn: func() func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+				calls := 0
+				return func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					calls++
+					if calls > 2 {
+						return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+					}
+					return objectWithUID("uid-1"), nil
+				}
+			}(),
+			wantSuccess: true,
+			wantDeleted: []string{"first"},
+		},
+		{
+			name:    "wait timeout reports leftovers",
+			args:    map[string]any{"wait": true, "timeout": "1s"},
+			tracked: tracked[:1],
+			getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+				return objectWithUID("uid-1"), nil
+			},
+			wantSuccess:   false,
+			wantDeleted:   []string{"first"},
+			wantOutputs:   map[string]string{"leftovers": "1", "leftoverResources": "ConfigMap default/first"},
+			wantRetracked: []string{"first"},
+		},
+		{
+			name:    "failed and still present leftovers are retracked in creation order",
+			args:    map[string]any{"wait": true, "timeout": "1s"},
+			tracked: tracked,
+			getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+				return objectWithUID(map[string]string{"first": "uid-1", "second": "uid-2", "third": "uid-3"}[name]), nil
+			},
+			deleteErr: map[string]error{
+				"first": errors.New("forbidden"),
+			},
+			wantSuccess:   false,
+			wantDeleted:   []string{"third", "second"},
+			wantOutputs:   map[string]string{"leftovers": "3"},
+			wantRetracked: []string{"first", "second", "third"},
+		},
+		{
+			name:          "invalid timeout",
+			args:          map[string]any{"timeout": "soon"},
+			tracked:       tracked,
+			wantSuccess:   false,
+			wantRetracked: []string{"first", "second", "third"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			client := &mockClient{
+				getFn: tt.getFn,
+				deleteFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string, opts metav1.DeleteOptions) error {
+					if err := tt.deleteErr[name]; err != nil {
+						return err
+					}
+					if opts.Preconditions == nil || opts.Preconditions.UID == nil {
+						t.Errorf("expected UID precondition when deleting %s", name)
+					}
+					deleted = append(deleted, name)
+					return nil
+				},
+			}
+
+			ext := &Extension{
+				Extension:        sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:           client,
+				trackedResources: slices.Clone(tt.tracked),
+			}
+
+			req := &sdk.OperationRequest{Args: tt.args}
+			result, err := ext.handleDeleteTracked(context.Background(), req)
+
+			if err != nil {
+				t.Fatalf("handleDeleteTracked() returned error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("handleDeleteTracked() success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if !slices.Equal(deleted, tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			for k, want := range tt.wantOutputs {
+				if got := result.Outputs[k]; got != want {
+					t.Errorf("output %s = %q, want %q", k, got, want)
+				}
+			}
+
+			var retracked []string
+			for _, r := range ext.trackedResources {
+				retracked = append(retracked, r.name)
+			}
+			if !slices.Equal(retracked, tt.wantRetracked) {
+				t.Errorf("tracked after delete = %v, want %v", retracked, tt.wantRetracked)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -20,6 +21,11 @@ func (e *Extension) handleWait(ctx context.Context, req *sdk.OperationRequest) (
 		return sdk.Failure(fmt.Errorf("args must be an object")), nil
 	}
 
+	actor, err := e.actorFor(ctx, args)
+	if err != nil {
+		return sdk.Failure(err), nil
+	}
+
 	ref, err := parseResourceRef(args)
 	if err != nil {
 		return sdk.Failure(err), nil
@@ -57,11 +63,21 @@ func (e *Extension) handleWait(ctx context.Context, req *sdk.OperationRequest) (
 		"condition": condition,
 		"status":    status,
 		"timeout":   timeoutStr,
+		"as":        actor.as,
 	})
 
+	// A forbidden impersonated request is the answer a permission check is
+	// after, so it ends the wait. The extension's own identity may still be
+	// granted access while it waits.
+	impersonated := actor.as != "" || actor.expectForbidden
 	var lastStatus string
+	var lastErr error
 	err = wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
-		obj, getErr := e.client.Get(ctx, gvr, ref.name, ref.namespace)
+		obj, getErr := actor.client.Get(ctx, gvr, ref.name, ref.namespace)
+		lastErr = getErr
+		if impersonated && apierrors.IsForbidden(getErr) {
+			return false, getErr
+		}
 		if getErr != nil {
 			return false, nil // Keep polling on transient errors
 		}
@@ -91,6 +107,22 @@ func (e *Extension) handleWait(ctx context.Context, req *sdk.OperationRequest) (
 		return false, nil
 	})
 
+	if err != nil && apierrors.IsForbidden(lastErr) {
+		// Report the permission error rather than a timeout, also when the
+		// extension's own requests stayed forbidden until the timeout.
+		err = lastErr
+		if !actor.expectForbidden {
+			e.LogError(ctx, "Condition wait forbidden", map[string]any{
+				"kind":  ref.kind,
+				"name":  ref.name,
+				"as":    actor.as,
+				"error": err.Error(),
+			})
+		}
+	}
+	if r := actor.forbidden(err, fmt.Sprintf("get %s/%s", ref.kind, ref.name)); r != nil {
+		return r, nil
+	}
 	if err != nil {
 		e.LogError(ctx, "Condition wait timed out", map[string]any{
 			"kind":       ref.kind,
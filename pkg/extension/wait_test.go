@@ -2,19 +2,22 @@ package extension
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestHandleWait(t *testing.T) {
 	tests := []struct {
-		name        string
-		args        any
-		client      *mockClient
-		wantSuccess bool
+		name           string
+		args           any
+		client         *mockClient
+		wantSuccess    bool
+		wantErrContain string
 	}{
 		{
 			name: "condition already met",
@@ -64,6 +67,55 @@ func TestHandleWait(t *testing.T) {
 			},
 			wantSuccess: false,
 		},
+		{
+			name: "forbidden for the extension keeps polling",
+			args: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "nginx", "namespace": "default"},
+				"condition":  "Available",
+				"timeout":    "3s",
+			},
+			client: func() *mockClient {
+				calls := 0
+				return &mockClient{
+					getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+						calls++
+						if calls == 1 {
+							// RBAC granted to the extension has not propagated yet.
+							return nil, apierrors.NewForbidden(gvr.GroupResource(), name, nil)
+						}
+						return &unstructured.Unstructured{
+							Object: map[string]any{
+								"status": map[string]any{
+									"conditions": []any{
+										map[string]any{"type": "Available", "status": "True"},
+									},
+								},
+							},
+						}, nil
+					},
+				}
+			}(),
+			wantSuccess: true,
+		},
+		{
+			name: "forbidden until the timeout is a permission error",
+			args: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "nginx", "namespace": "default"},
+				"condition":  "Available",
+				"timeout":    "1s",
+			},
+			client: &mockClient{
+				getFn: func(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (*unstructured.Unstructured, error) {
+					return nil, apierrors.NewForbidden(gvr.GroupResource(), name, nil)
+				},
+			},
+			wantSuccess:    false,
+			wantErrContain: "forbidden",
+		},
 		{
 			name: "missing condition field",
 			args: map[string]any{
@@ -92,6 +144,9 @@ func TestHandleWait(t *testing.T) {
 			if result.Success != tt.wantSuccess {
 				t.Errorf("handleWait() success = %v, want %v", result.Success, tt.wantSuccess)
 			}
+			if tt.wantErrContain != "" && (!strings.Contains(result.Error, tt.wantErrContain) || strings.Contains(result.Error, "timed out")) {
+				t.Errorf("expected error to contain %q and not report a timeout, got %q", tt.wantErrContain, result.Error)
+			}
 		})
 	}
 }
@@ -0,0 +1,315 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	roleGVR               = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"}
+	clusterRoleGVR        = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+	clusterRoleBindingGVR = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}
+)
+
+// rbacGrant is a subject allowed an action through a binding.
+type rbacGrant struct {
+	Subject string `json:"subject"`
+	Binding string `json:"binding"`
+	Role    string `json:"role"`
+}
+
+// rbacSubjectName identifies a binding subject, e.g. "User:bob", "Group:devs"
+// or "ServiceAccount:team-a/agent".
+func rbacSubjectName(s rbacv1.Subject) string {
+	if s.Kind == rbacv1.ServiceAccountKind {
+		return fmt.Sprintf("%s:%s/%s", s.Kind, s.Namespace, s.Name)
+	}
+	return s.Kind + ":" + s.Name
+}
+
+// ruleAllows reports whether an RBAC rule grants the access request, following
+// the matching of the RBAC authorizer.
+func ruleAllows(rule rbacv1.PolicyRule, r AccessRequest) bool {
+	if !slices.Contains(rule.Verbs, r.Verb) && !slices.Contains(rule.Verbs, rbacv1.VerbAll) {
+		return false
+	}
+
+	if r.NonResourceURL != "" {
+		return matchesURL(rule.NonResourceURLs, r.NonResourceURL)
+	}
+
+	if !slices.Contains(rule.APIGroups, r.APIGroup) && !slices.Contains(rule.APIGroups, rbacv1.APIGroupAll) {
+		return false
+	}
+
+	resource := r.Resource
+	if r.Subresource != "" {
+		resource += "/" + r.Subresource
+	}
+	if !slices.ContainsFunc(rule.Resources, func(res string) bool {
+		return res == rbacv1.ResourceAll || res == resource ||
+			(r.Subresource != "" && res == "*/"+r.Subresource)
+	}) {
+		return false
+	}
+
+	return len(rule.ResourceNames) == 0 || slices.Contains(rule.ResourceNames, r.ResourceName)
+}
+
+// listRBAC lists objects of an RBAC resource and converts them to the typed
+// form. An empty namespace lists cluster-scoped objects.
+func listRBAC[T any](ctx context.Context, client ResourceClient, gvr schema.GroupVersionResource, namespace string) ([]T, error) {
+	list, err := client.List(ctx, gvr, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+	items := make([]T, len(list.Items))
+	for i, item := range list.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &items[i]); err != nil {
+			return nil, fmt.Errorf("failed to convert %s %s: %w", gvr.Resource, item.GetName(), err)
+		}
+	}
+	return items, nil
+}
+
+// findRBACGrants returns the subjects whose RoleBindings in the request's
+// namespace or ClusterRoleBindings grant the access request. Bindings that
+// reference a missing role grant nothing and are reported as missing.
+func findRBACGrants(ctx context.Context, client ResourceClient, r AccessRequest) ([]rbacGrant, []string, error) {
+	clusterRoles, err := listRBAC[rbacv1.ClusterRole](ctx, client, clusterRoleGVR, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	clusterRules := make(map[string][]rbacv1.PolicyRule, len(clusterRoles))
+	for _, role := range clusterRoles {
+		clusterRules[role.Name] = role.Rules
+	}
+
+	var grants []rbacGrant
+	var missing []string
+	addGrants := func(binding string, subjects []rbacv1.Subject, role string, rules []rbacv1.PolicyRule) {
+		if !slices.ContainsFunc(rules, func(rule rbacv1.PolicyRule) bool { return ruleAllows(rule, r) }) {
+			return
+		}
+		for _, s := range subjects {
+			grants = append(grants, rbacGrant{Subject: rbacSubjectName(s), Binding: binding, Role: role})
+		}
+	}
+
+	clusterBindings, err := listRBAC[rbacv1.ClusterRoleBinding](ctx, client, clusterRoleBindingGVR, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, b := range clusterBindings {
+		binding := "ClusterRoleBinding/" + b.Name
+		role := "ClusterRole/" + b.RoleRef.Name
+		rules, ok := clusterRules[b.RoleRef.Name]
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%s references missing %s", binding, role))
+			continue
+		}
+		// Non-resource URLs are only granted cluster-wide; for namespaced
+		// resources a ClusterRoleBinding grants access in every namespace.
+		addGrants(binding, b.Subjects, role, rules)
+	}
+
+	if r.Namespace == "" || r.NonResourceURL != "" {
+		return grants, missing, nil
+	}
+
+	roles, err := listRBAC[rbacv1.Role](ctx, client, roleGVR, r.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	roleRules := make(map[string][]rbacv1.PolicyRule, len(roles))
+	for _, role := range roles {
+		roleRules[role.Name] = role.Rules
+	}
+
+	bindings, err := listRBAC[rbacv1.RoleBinding](ctx, client, roleBindingGVR, r.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, b := range bindings {
+		binding := fmt.Sprintf("RoleBinding/%s/%s", b.Namespace, b.Name)
+		role := b.RoleRef.Kind + "/" + b.RoleRef.Name
+		var rules []rbacv1.PolicyRule
+		var ok bool
+		if b.RoleRef.Kind == "ClusterRole" {
+			rules, ok = clusterRules[b.RoleRef.Name]
+		} else {
+			rules, ok = roleRules[b.RoleRef.Name]
+		}
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%s references missing %s", binding, role))
+			continue
+		}
+		addGrants(binding, b.Subjects, role, rules)
+	}
+
+	return grants, missing, nil
+}
+
+// grantSubjects returns the sorted, distinct subjects of grants.
+func grantSubjects(grants []rbacGrant) []string {
+	subjects := make([]string, 0, len(grants))
+	for _, g := range grants {
+		subjects = append(subjects, g.Subject)
+	}
+	slices.Sort(subjects)
+	return slices.Compact(subjects)
+}
+
+// checkSubjectExpectations compares the allowed subjects against the
+// expect argument: subjects is the exact set, includes and excludes list
+// subjects that must or must not be allowed.
+func checkSubjectExpectations(expect map[string]any, subjects []string) ([]string, error) {
+	exact, err := stringListArg(expect, "subjects")
+	if err != nil {
+		return nil, fmt.Errorf("expect.%w", err)
+	}
+	includes, err := stringListArg(expect, "includes")
+	if err != nil {
+		return nil, fmt.Errorf("expect.%w", err)
+	}
+	excludes, err := stringListArg(expect, "excludes")
+	if err != nil {
+		return nil, fmt.Errorf("expect.%w", err)
+	}
+
+	var problems []string
+	if _, ok := expect["subjects"]; ok {
+		includes = append(includes, exact...)
+		for _, s := range subjects {
+			if !slices.Contains(exact, s) {
+				problems = append(problems, fmt.Sprintf("unexpected subject %s", s))
+			}
+		}
+	}
+	for _, s := range dedupe(includes) {
+		if !slices.Contains(subjects, s) {
+			problems = append(problems, fmt.Sprintf("missing subject %s", s))
+		}
+	}
+	for _, s := range excludes {
+		if slices.Contains(subjects, s) {
+			problems = append(problems, fmt.Sprintf("subject %s should not be allowed", s))
+		}
+	}
+	return problems, nil
+}
+
+// handleWhoCan reports which subjects RBAC allows to perform an action and
+// through which bindings, similar to kubectl who-can.
+func (e *Extension) handleWhoCan(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+	if e.client == nil {
+		return sdk.Failure(fmt.Errorf("kubernetes client not initialized")), nil
+	}
+
+	args, ok := req.Args.(map[string]any)
+	if !ok {
+		return sdk.Failure(fmt.Errorf("args must be an object")), nil
+	}
+
+	var access AccessRequest
+	parseAccessAttributes(&access, args)
+	if err := access.validate(); err != nil {
+		return sdk.Failure(err), nil
+	}
+
+	var expect map[string]any
+	if raw, ok := args["expect"]; ok {
+		if expect, ok = raw.(map[string]any); !ok {
+			return sdk.Failure(fmt.Errorf("expect must be an object")), nil
+		}
+	}
+
+	if access.NonResourceURL == "" {
+		lists, err := e.kube(ctx).DiscoverResources(ctx)
+		if err != nil {
+			return sdk.Failure(fmt.Errorf("failed to discover API resources: %w", err)), nil
+		}
+		if err := access.resolveResource(ctx, e.kube(ctx), lists); err != nil {
+			return sdk.Failure(err), nil
+		}
+	}
+
+	e.LogInfo(ctx, "Finding subjects allowed by RBAC", map[string]any{
+		"verb":           access.Verb,
+		"resource":       access.Resource,
+		"subresource":    access.Subresource,
+		"apiGroup":       access.APIGroup,
+		"nonResourceURL": access.NonResourceURL,
+		"namespace":      access.Namespace,
+	})
+
+	grants, missing, err := findRBACGrants(ctx, e.kube(ctx), access)
+	if err != nil {
+		e.LogError(ctx, "Failed to read RBAC bindings", map[string]any{
+			"error": err.Error(),
+		})
+		return sdk.Failure(err), nil
+	}
+	for _, m := range missing {
+		e.LogWarn(ctx, "Binding references a missing role", map[string]any{
+			"binding": m,
+		})
+	}
+
+	subjects := grantSubjects(grants)
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SUBJECT\tBINDING\tROLE")
+	for _, g := range grants {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", g.Subject, g.Binding, g.Role)
+	}
+	w.Flush()
+
+	subjectsJSON, err := json.Marshal(subjects)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to marshal subjects: %w", err)), nil
+	}
+	grantsJSON, err := json.Marshal(grants)
+	if err != nil {
+		return sdk.Failure(fmt.Errorf("failed to marshal grants: %w", err)), nil
+	}
+	outputs := map[string]string{
+		"subjects": string(subjectsJSON),
+		"grants":   string(grantsJSON),
+		"count":    fmt.Sprintf("%d", len(subjects)),
+		"table":    table.String(),
+	}
+
+	summary := fmt.Sprintf("%d subject(s) can %s %s", len(subjects), access.Verb, access.target())
+
+	if expect != nil {
+		problems, err := checkSubjectExpectations(expect, subjects)
+		if err != nil {
+			return sdk.Failure(err), nil
+		}
+		if len(problems) > 0 {
+			e.LogError(ctx, "Subject expectations not met", map[string]any{
+				"problems": problems,
+			})
+			return &sdk.OperationResult{
+				Success: false,
+				Message: fmt.Sprintf("%s:\n%s\n%s", summary, strings.Join(problems, "\n"), table.String()),
+				Error:   "subject expectations not met",
+				Outputs: outputs,
+			}, nil
+		}
+	}
+
+	return sdk.SuccessWithOutputs(summary+":\n"+table.String(), outputs), nil
+}
@@ -0,0 +1,286 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func toUnstructuredList(t *testing.T, objs ...any) *unstructured.UnstructuredList {
+	t.Helper()
+	list := &unstructured.UnstructuredList{}
+	for _, obj := range objs {
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			t.Fatalf("failed to convert %T: %v", obj, err)
+		}
+		list.Items = append(list.Items, unstructured.Unstructured{Object: m})
+	}
+	return list
+}
+
+func TestHandleWhoCan(t *testing.T) {
+	meta := func(namespace, name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: namespace, Name: name}
+	}
+	user := func(name string) rbacv1.Subject { return rbacv1.Subject{Kind: rbacv1.UserKind, Name: name} }
+
+	rbac := map[string][]any{
+		"clusterroles": {
+			&rbacv1.ClusterRole{ObjectMeta: meta("", "cluster-admin"), Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+				{Verbs: []string{"*"}, NonResourceURLs: []string{"*"}},
+			}},
+			&rbacv1.ClusterRole{ObjectMeta: meta("", "secret-reader"), Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"secrets"}},
+			}},
+			&rbacv1.ClusterRole{ObjectMeta: meta("", "metrics"), Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"get"}, NonResourceURLs: []string{"/metrics"}},
+			}},
+		},
+		"clusterrolebindings": {
+			&rbacv1.ClusterRoleBinding{
+				ObjectMeta: meta("", "admins"),
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:masters"}},
+			},
+			&rbacv1.ClusterRoleBinding{
+				ObjectMeta: meta("", "prometheus"),
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "metrics"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "monitoring", Name: "prometheus"}},
+			},
+			&rbacv1.ClusterRoleBinding{
+				ObjectMeta: meta("", "dangling"),
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "deleted"},
+				Subjects:   []rbacv1.Subject{user("mallory")},
+			},
+		},
+		"roles": {
+			&rbacv1.Role{ObjectMeta: meta("team-a", "secret-deleter"), Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"delete"}, APIGroups: []string{""}, Resources: []string{"secrets"}},
+			}},
+			&rbacv1.Role{ObjectMeta: meta("team-a", "db-password"), Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"db-password"}},
+			}},
+			&rbacv1.Role{ObjectMeta: meta("team-a", "log-reader"), Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}},
+			}},
+		},
+		"rolebindings": {
+			&rbacv1.RoleBinding{
+				ObjectMeta: meta("team-a", "bob-deletes-secrets"),
+				RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "secret-deleter"},
+				Subjects:   []rbacv1.Subject{user("bob")},
+			},
+			&rbacv1.RoleBinding{
+				ObjectMeta: meta("team-a", "readers"),
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "secret-reader"},
+				Subjects: []rbacv1.Subject{
+					user("carol"),
+					{Kind: rbacv1.ServiceAccountKind, Namespace: "team-a", Name: "agent"},
+				},
+			},
+			&rbacv1.RoleBinding{
+				ObjectMeta: meta("team-a", "dave-db-password"),
+				RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "db-password"},
+				Subjects:   []rbacv1.Subject{user("dave")},
+			},
+			&rbacv1.RoleBinding{
+				ObjectMeta: meta("team-a", "erin-logs"),
+				RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "log-reader"},
+				Subjects:   []rbacv1.Subject{user("erin")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		args           any
+		listErr        error
+		clientNil      bool
+		wantSuccess    bool
+		wantErrContain string
+		wantSubjects   []string
+		wantBinding    string
+	}{
+		{
+			name:        "client not initialized",
+			args:        map[string]any{"verb": "get", "resource": "secrets"},
+			clientNil:   true,
+			wantSuccess: false,
+		},
+		{
+			name:           "missing verb",
+			args:           map[string]any{"resource": "secrets"},
+			wantSuccess:    false,
+			wantErrContain: "verb is required",
+		},
+		{
+			name:           "unknown resource",
+			args:           map[string]any{"verb": "get", "resource": "secretz"},
+			wantSuccess:    false,
+			wantErrContain: `unknown resource "secretz"`,
+		},
+		{
+			name:         "role binding grants delete",
+			args:         map[string]any{"verb": "delete", "resource": "secrets", "namespace": "team-a"},
+			wantSuccess:  true,
+			wantSubjects: []string{"Group:system:masters", "User:bob"},
+			wantBinding:  "RoleBinding/team-a/bob-deletes-secrets",
+		},
+		{
+			name:         "role binding to cluster role",
+			args:         map[string]any{"verb": "list", "resource": "secret", "namespace": "team-a"},
+			wantSuccess:  true,
+			wantSubjects: []string{"Group:system:masters", "ServiceAccount:team-a/agent", "User:carol"},
+			wantBinding:  "RoleBinding/team-a/readers",
+		},
+		{
+			name:         "other namespace only sees cluster role bindings",
+			args:         map[string]any{"verb": "delete", "resource": "secrets", "namespace": "team-b"},
+			wantSuccess:  true,
+			wantSubjects: []string{"Group:system:masters"},
+		},
+		{
+			name:         "resource names restrict rules",
+			args:         map[string]any{"verb": "get", "resource": "secrets", "resourceName": "db-password", "namespace": "team-a"},
+			wantSuccess:  true,
+			wantSubjects: []string{"Group:system:masters", "ServiceAccount:team-a/agent", "User:carol", "User:dave"},
+		},
+		{
+			name:         "subresource",
+			args:         map[string]any{"verb": "get", "resource": "pods/log", "namespace": "team-a"},
+			wantSuccess:  true,
+			wantSubjects: []string{"Group:system:masters", "User:erin"},
+		},
+		{
+			name:         "non-resource URL",
+			args:         map[string]any{"verb": "get", "nonResourceURL": "/metrics"},
+			wantSuccess:  true,
+			wantSubjects: []string{"Group:system:masters", "ServiceAccount:monitoring/prometheus"},
+			wantBinding:  "ClusterRoleBinding/prometheus",
+		},
+		{
+			name: "expected subjects match",
+			args: map[string]any{
+				"verb": "delete", "resource": "secrets", "namespace": "team-a",
+				"expect": map[string]any{"subjects": []any{"User:bob", "Group:system:masters"}},
+			},
+			wantSuccess:  true,
+			wantSubjects: []string{"Group:system:masters", "User:bob"},
+		},
+		{
+			name: "unexpected subject",
+			args: map[string]any{
+				"verb": "delete", "resource": "secrets", "namespace": "team-a",
+				"expect": map[string]any{"subjects": []any{"Group:system:masters"}},
+			},
+			wantSuccess:    false,
+			wantErrContain: "subject expectations not met",
+			wantSubjects:   []string{"Group:system:masters", "User:bob"},
+		},
+		{
+			name: "excluded subject allowed",
+			args: map[string]any{
+				"verb": "delete", "resource": "secrets", "namespace": "team-a",
+				"expect": map[string]any{"excludes": []any{"User:bob"}},
+			},
+			wantSuccess:    false,
+			wantErrContain: "subject expectations not met",
+		},
+		{
+			name: "included subject missing",
+			args: map[string]any{
+				"verb": "delete", "resource": "secrets", "namespace": "team-a",
+				"expect": map[string]any{"includes": []any{"User:carol"}},
+			},
+			wantSuccess:    false,
+			wantErrContain: "subject expectations not met",
+		},
+		{
+			name: "invalid expect",
+			args: map[string]any{
+				"verb": "delete", "resource": "secrets", "namespace": "team-a",
+				"expect": map[string]any{"subjects": "User:bob"},
+			},
+			wantSuccess: false,
+		},
+		{
+			name:           "list error",
+			args:           map[string]any{"verb": "get", "resource": "secrets", "namespace": "team-a"},
+			listErr:        fmt.Errorf("forbidden"),
+			wantSuccess:    false,
+			wantErrContain: "failed to list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{
+				discoverResourcesFn: func(ctx context.Context) ([]*metav1.APIResourceList, error) {
+					return accessTestResources, nil
+				},
+				discoverGroupVersionFn: discoverAccessTestGroupVersion,
+				listFn: func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					if tt.listErr != nil {
+						return nil, tt.listErr
+					}
+					var objs []any
+					for _, obj := range rbac[gvr.Resource] {
+						if o, ok := obj.(metav1.Object); ok && o.GetNamespace() == namespace {
+							objs = append(objs, obj)
+						}
+					}
+					return toUnstructuredList(t, objs...), nil
+				},
+			}
+			ext := &Extension{
+				Extension: sdk.NewExtension(sdk.ExtensionInfo{Name: "test"}),
+				client:    client,
+			}
+			if tt.clientNil {
+				ext.client = nil
+			}
+
+			result, err := ext.handleWhoCan(context.Background(), &sdk.OperationRequest{Args: tt.args})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if tt.wantErrContain != "" && !strings.Contains(result.Error, tt.wantErrContain) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErrContain, result.Error)
+			}
+
+			if tt.wantSubjects != nil {
+				var subjects []string
+				if err := json.Unmarshal([]byte(result.Outputs["subjects"]), &subjects); err != nil {
+					t.Fatalf("invalid subjects output: %v", err)
+				}
+				if !slices.Equal(subjects, tt.wantSubjects) {
+					t.Errorf("subjects = %v, want %v", subjects, tt.wantSubjects)
+				}
+			}
+			if tt.wantBinding != "" {
+				var grants []rbacGrant
+				if err := json.Unmarshal([]byte(result.Outputs["grants"]), &grants); err != nil {
+					t.Fatalf("invalid grants output: %v", err)
+				}
+				if !slices.ContainsFunc(grants, func(g rbacGrant) bool { return g.Binding == tt.wantBinding }) {
+					t.Errorf("expected a grant through %s, got %v", tt.wantBinding, grants)
+				}
+			}
+		})
+	}
+}